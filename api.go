@@ -7,6 +7,8 @@ import (
 	"runtime/debug"
 	"sync/atomic"
 	"time"
+
+	"github.com/jizhuozhi/go-future/executors"
 )
 
 var ErrPanic = errors.New("async panic")
@@ -55,9 +57,28 @@ func CtxAsync[T any](ctx context.Context, f func(ctx context.Context) (T, error)
 	return CtxSubmit(ctx, executor, f)
 }
 
+// CtxAsyncTimeout is like CtxAsync but times out after d, see CtxSubmitTimeout.
+func CtxAsyncTimeout[T any](ctx context.Context, d time.Duration, f func(ctx context.Context) (T, error)) *Future[T] {
+	return CtxSubmitTimeout(ctx, executor, d, f)
+}
+
+// AsyncPriority is like Async but submits f with the given priority, see SubmitPriority.
+func AsyncPriority[T any](priority int, f func() (T, error)) *Future[T] {
+	return SubmitPriority(executor, priority, f)
+}
+
+// CtxAsyncPriority is like CtxAsync but submits f with the given priority, see SubmitPriority.
+func CtxAsyncPriority[T any](ctx context.Context, priority int, f func(ctx context.Context) (T, error)) *Future[T] {
+	return CtxSubmitPriority(ctx, executor, priority, f)
+}
+
+// Submit executes f on the given Executor and returns a Future of its result.
+//
+// If the executor rejects the task (see executors.TrySubmitter), the Future is completed
+// with the rejection error immediately, e.g. executors.ErrExecutorClosed after shutdown.
 func Submit[T any](e Executor, f func() (T, error)) *Future[T] {
 	s := &state[T]{}
-	e.Submit(func() {
+	reject(s, executors.TrySubmit(e, func() {
 		var val T
 		var err error
 		defer func() {
@@ -67,13 +88,59 @@ func Submit[T any](e Executor, f func() (T, error)) *Future[T] {
 			s.set(val, err)
 		}()
 		val, err = f()
-	})
+	}))
 	return &Future[T]{state: s}
 }
 
+// CtxSubmit is like Submit but passes ctx to f.
+//
+// If e waits before accepting the task (see executors.ContextSubmitter, e.g. executors.LimitedExecutor),
+// the waiting is cancelled when ctx is done, and the Future is completed with ctx.Err().
 func CtxSubmit[T any](ctx context.Context, e Executor, f func(ctx context.Context) (T, error)) *Future[T] {
 	s := &state[T]{}
-	e.Submit(func() {
+	reject(s, executors.TrySubmitContext(ctx, e, task(ctx, s, f)))
+	return &Future[T]{state: s}
+}
+
+// CtxSubmitTimeout is like CtxSubmit but the returned Future fails with ErrTimeout if f is not done within d.
+//
+// Unlike Timeout, f is called with a context derived from ctx with the deadline of d, which is cancelled
+// when the timeout fires, so that timed-out tasks (e.g. RPCs) actually stop. If f fails because of the
+// deadline of the derived context, the Future also fails with ErrTimeout.
+//
+// NOTE: The deadline of the derived context is measured by the time package rather than the Clock of go-future.
+func CtxSubmitTimeout[T any](ctx context.Context, e Executor, d time.Duration, f func(ctx context.Context) (T, error)) *Future[T] {
+	tctx, cancel := context.WithTimeout(ctx, d)
+	return TimeoutCancel(CtxSubmit(tctx, e, func(tctx context.Context) (T, error) {
+		val, err := f(tctx)
+		if err != nil && tctx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			var zero T
+			return zero, ErrTimeout
+		}
+		return val, err
+	}), d, cancel)
+}
+
+// SubmitPriority is like Submit but submits f with the given priority, larger runs earlier.
+//
+// The priority only takes effect if e implements executors.PrioritySubmitter (e.g. executors.PriorityExecutor),
+// otherwise it is ignored.
+func SubmitPriority[T any](e Executor, priority int, f func() (T, error)) *Future[T] {
+	return CtxSubmitPriority(context.Background(), e, priority, func(context.Context) (T, error) {
+		return f()
+	})
+}
+
+// CtxSubmitPriority is like CtxSubmit but submits f with the given priority, see SubmitPriority.
+func CtxSubmitPriority[T any](ctx context.Context, e Executor, priority int, f func(ctx context.Context) (T, error)) *Future[T] {
+	s := &state[T]{}
+	reject(s, executors.TrySubmitPriority(e, priority, task(ctx, s, f)))
+	return &Future[T]{state: s}
+}
+
+// task wraps f into a task which recovers panics and completes s with the result of f.
+func task[T any](ctx context.Context, s *state[T], f func(ctx context.Context) (T, error)) func() {
+	return func() {
 		var val T
 		var err error
 		defer func() {
@@ -83,8 +150,15 @@ func CtxSubmit[T any](ctx context.Context, e Executor, f func(ctx context.Contex
 			s.set(val, err)
 		}()
 		val, err = f(ctx)
-	})
-	return &Future[T]{state: s}
+	}
+}
+
+// reject completes s with err if the executor rejected the task.
+func reject[T any](s *state[T], err error) {
+	if err != nil {
+		var zero T
+		s.set(zero, err)
+	}
 }
 
 func Done[T any](val T) *Future[T] {
@@ -182,17 +256,40 @@ func AllOf[T any](fs ...*Future[T]) *Future[[]T] {
 	return &Future[[]T]{state: s}
 }
 
+// Timeout returns a Future which is completed with the result of f, or fails with ErrTimeout
+// if f is not done within d, measured by the Clock of go-future.
 func Timeout[T any](f *Future[T], d time.Duration) *Future[T] {
+	return TimeoutWithClock(clock, f, d)
+}
+
+// TimeoutWithClock is like Timeout but measures d with the given Clock.
+func TimeoutWithClock[T any](c Clock, f *Future[T], d time.Duration) *Future[T] {
+	return timeout(c, f, d, nil)
+}
+
+// TimeoutCancel is like Timeout but calls cancel once the returned Future is done, either f is done or timed out,
+// so that the task producing f is cancelled when it is timed out, e.g. the context.CancelFunc of the task.
+func TimeoutCancel[T any](f *Future[T], d time.Duration, cancel context.CancelFunc) *Future[T] {
+	return timeout(clock, f, d, cancel)
+}
+
+func timeout[T any](c Clock, f *Future[T], d time.Duration, cancel context.CancelFunc) *Future[T] {
 	var done uint32
 	s := &state[T]{}
-	timer := time.AfterFunc(d, func() {
+	timer := c.AfterFunc(d, func() {
 		if atomic.CompareAndSwapUint32(&done, 0, 1) {
+			if cancel != nil {
+				cancel()
+			}
 			var zero T
 			s.set(zero, ErrTimeout)
 		}
 	})
 	f.state.subscribe(func(val T, err error) {
 		if atomic.CompareAndSwapUint32(&done, 0, 1) {
+			if cancel != nil {
+				cancel()
+			}
 			s.set(val, err)
 			timer.Stop()
 		}
@@ -200,6 +297,12 @@ func Timeout[T any](f *Future[T], d time.Duration) *Future[T] {
 	return &Future[T]{state: s}
 }
 
+// Until is like Timeout but fails with ErrTimeout if f is not done before t.
 func Until[T any](f *Future[T], t time.Time) *Future[T] {
-	return Timeout(f, time.Until(t))
+	return UntilWithClock(clock, f, t)
+}
+
+// UntilWithClock is like Until but measures t with the given Clock.
+func UntilWithClock[T any](c Clock, f *Future[T], t time.Time) *Future[T] {
+	return TimeoutWithClock(c, f, t.Sub(c.Now()))
 }
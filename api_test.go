@@ -425,3 +425,58 @@ func TestUntil(t *testing.T) {
 		assert.NoError(t, err)
 	}
 }
+
+func TestTimeoutCancel(t *testing.T) {
+	{
+		cancelled := make(chan struct{})
+		f := TimeoutCancel(NewPromise[int]().Future(), time.Millisecond, func() {
+			close(cancelled)
+		})
+		_, err := f.Get()
+		assert.ErrorIs(t, err, ErrTimeout)
+		<-cancelled
+	}
+	{
+		cancelled := false
+		f := TimeoutCancel(Done(1), time.Second, func() {
+			cancelled = true
+		})
+		val, err := f.Get()
+		assert.Equal(t, 1, val)
+		assert.NoError(t, err)
+		assert.True(t, cancelled)
+	}
+}
+
+func TestCtxAsyncTimeout(t *testing.T) {
+	{
+		stopped := make(chan error, 1)
+		f := CtxAsyncTimeout(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			<-ctx.Done()
+			stopped <- ctx.Err()
+			return 0, ctx.Err()
+		})
+		_, err := f.Get()
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.Error(t, <-stopped)
+	}
+	{
+		f := CtxAsyncTimeout(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+			return 1, nil
+		})
+		val, err := f.Get()
+		assert.Equal(t, 1, val)
+		assert.NoError(t, err)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		f := CtxAsyncTimeout(ctx, time.Second, func(ctx context.Context) (int, error) {
+			return 0, ctx.Err()
+		})
+		_, err := f.Get()
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
@@ -0,0 +1,150 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+var ErrKeyNotFound = errors.New("batch key not found")
+
+// Func loads the values of a batch of distinct keys. Keys missing in the returned map fail with ErrKeyNotFound.
+//
+// If an Errors is returned, the keys in it fail with their own errors and the others get their values,
+// otherwise a non-nil error fails all the keys in the batch.
+type Func[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Errors is an error with a per-key error, returned by Func when only some keys failed.
+type Errors[K comparable] map[K]error
+
+func (e Errors[K]) Error() string {
+	for _, err := range e {
+		return fmt.Sprintf("batch failed for %d keys, e.g. %v", len(e), err)
+	}
+	return "batch failed for 0 keys"
+}
+
+// Options configures a Loader.
+type Options struct {
+	// Wait is how long a batch collects keys since its first key before it is dispatched, default 1ms.
+	Wait time.Duration
+	// MaxBatch dispatches a batch immediately once it has MaxBatch distinct keys, zero means unlimited.
+	MaxBatch int
+	// Clock measures Wait, default is the Clock of go-future.
+	Clock future.Clock
+}
+
+// Loader coalesces the Loads made within a time window into a single call of Func, like DataLoader,
+// to solve N+1 calls to a service supporting batch requests.
+//
+//	users := batch.New(ctx, func(ctx context.Context, ids []string) (map[string]*User, error) {
+//	    return client.BatchGetUsers(ctx, ids)
+//	}, batch.Options{Wait: time.Millisecond, MaxBatch: 100})
+//	for _, post := range posts {
+//	    post.Author = users.Load(post.AuthorID)
+//	}
+//
+// Loads of the same key in a batch share the key in the call. Func is called with the context of the Loader
+// on the executor of go-future, so a Loader is usually created per request.
+type Loader[K comparable, V any] struct {
+	ctx  context.Context
+	fn   Func[K, V]
+	opts Options
+
+	mu  sync.Mutex
+	cur *pending[K, V]
+}
+
+type pending[K comparable, V any] struct {
+	keys     []K
+	promises map[K][]*future.Promise[V]
+	timer    future.Timer
+}
+
+// New creates a Loader calling fn with ctx.
+func New[K comparable, V any](ctx context.Context, fn Func[K, V], opts Options) *Loader[K, V] {
+	if opts.Wait <= 0 {
+		opts.Wait = time.Millisecond
+	}
+	if opts.Clock == nil {
+		opts.Clock = future.DefaultClock()
+	}
+	return &Loader[K, V]{ctx: ctx, fn: fn, opts: opts}
+}
+
+// Load adds key to the current batch and returns the Future of its value.
+func (l *Loader[K, V]) Load(key K) *future.Future[V] {
+	p := future.NewPromise[V]()
+
+	l.mu.Lock()
+	b := l.cur
+	if b == nil {
+		b = &pending[K, V]{promises: make(map[K][]*future.Promise[V])}
+		l.cur = b
+		b.timer = l.opts.Clock.AfterFunc(l.opts.Wait, func() {
+			l.mu.Lock()
+			if l.cur != b {
+				// dispatched by MaxBatch
+				l.mu.Unlock()
+				return
+			}
+			l.cur = nil
+			l.mu.Unlock()
+			l.dispatch(b)
+		})
+	}
+	if _, ok := b.promises[key]; !ok {
+		b.keys = append(b.keys, key)
+	}
+	b.promises[key] = append(b.promises[key], p)
+	full := l.opts.MaxBatch > 0 && len(b.keys) >= l.opts.MaxBatch
+	if full {
+		l.cur = nil
+	}
+	l.mu.Unlock()
+
+	if full {
+		b.timer.Stop()
+		l.dispatch(b)
+	}
+	return p.Future()
+}
+
+// LoadAll is like Load for each of keys, and returns the Future of their values in order,
+// which fails with the first error if any.
+func (l *Loader[K, V]) LoadAll(keys ...K) *future.Future[[]V] {
+	fs := make([]*future.Future[V], len(keys))
+	for i, key := range keys {
+		fs[i] = l.Load(key)
+	}
+	return future.AllOf(fs...)
+}
+
+func (l *Loader[K, V]) dispatch(b *pending[K, V]) {
+	future.CtxAsync(l.ctx, func(ctx context.Context) (map[K]V, error) {
+		return l.fn(ctx, b.keys)
+	}).Subscribe(func(vals map[K]V, err error) {
+		var errs Errors[K]
+		if errors.As(err, &errs) {
+			err = nil
+		}
+		for key, ps := range b.promises {
+			val, ok := vals[key]
+			kerr := err
+			if kerr == nil {
+				if e, failed := errs[key]; failed {
+					kerr = e
+				} else if !ok {
+					kerr = ErrKeyNotFound
+				}
+			}
+			for _, p := range ps {
+				p.Set(val, kerr)
+			}
+		}
+	})
+}
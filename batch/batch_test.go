@@ -0,0 +1,113 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizhuozhi/go-future"
+	"github.com/jizhuozhi/go-future/futuretest"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) fn(ctx context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, keys)
+	r.mu.Unlock()
+	vals := make(map[int]string, len(keys))
+	for _, key := range keys {
+		if key >= 0 {
+			vals[key] = strconv.Itoa(key)
+		}
+	}
+	return vals, nil
+}
+
+func (r *recorder) get() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.batches
+}
+
+func TestLoaderWindow(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var r recorder
+	l := New(context.Background(), r.fn, Options{Wait: time.Millisecond, Clock: clock})
+
+	f1 := l.Load(1)
+	f2 := l.Load(2)
+	f3 := l.Load(1)
+	assert.Empty(t, r.get())
+	clock.Advance(time.Millisecond)
+
+	val, err := f1.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", val)
+	val, _ = f2.Get()
+	assert.Equal(t, "2", val)
+	val, _ = f3.Get()
+	assert.Equal(t, "1", val)
+	assert.Equal(t, [][]int{{1, 2}}, r.get())
+
+	// a new batch after dispatched
+	f4 := l.Load(3)
+	clock.Advance(time.Millisecond)
+	val, _ = f4.Get()
+	assert.Equal(t, "3", val)
+	assert.Equal(t, [][]int{{1, 2}, {3}}, r.get())
+}
+
+func TestLoaderMaxBatch(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var r recorder
+	l := New(context.Background(), r.fn, Options{Wait: time.Second, MaxBatch: 2, Clock: clock})
+
+	vals, err := l.LoadAll(1, 2).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, vals)
+
+	f := l.Load(3)
+	assert.Equal(t, 1, clock.Timers())
+	clock.Advance(time.Second)
+	val, _ := f.Get()
+	assert.Equal(t, "3", val)
+	assert.Equal(t, [][]int{{1, 2}, {3}}, r.get())
+}
+
+func TestLoaderErrors(t *testing.T) {
+	errFoo := errors.New("foo")
+	errBar := errors.New("bar")
+	var r recorder
+	l := New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		vals, _ := r.fn(ctx, keys)
+		return vals, Errors[int]{2: errBar}
+	}, Options{MaxBatch: 4})
+
+	fs := []*future.Future[string]{l.Load(1), l.Load(2), l.Load(-1), l.Load(3)}
+	_, err := fs[0].Get()
+	assert.NoError(t, err)
+	_, err = fs[1].Get()
+	assert.Equal(t, errBar, err)
+	_, err = fs[2].Get()
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	l = New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errFoo
+	}, Options{MaxBatch: 2})
+	_, err = l.LoadAll(1, 2).Get()
+	assert.Equal(t, errFoo, err)
+
+	l = New(context.Background(), func(ctx context.Context, keys []int) (map[int]string, error) {
+		panic("baz")
+	}, Options{})
+	_, err = l.Load(1).Get()
+	assert.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// StateClosed lets all requests through and tracks their failures.
+	StateClosed State = iota
+	// StateOpen fails all requests immediately with ErrCircuitOpen.
+	StateOpen
+	// StateHalfOpen lets a limited number of probe requests through to decide whether to close or reopen.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Policy configures when a Breaker opens and recovers. Zero fields take the documented defaults.
+type Policy struct {
+	// Window is the duration of the rolling window tracking failures, default 10s.
+	Window time.Duration
+	// Buckets is the number of buckets the window is divided into, default 10.
+	Buckets int
+	// MinRequests is the minimum number of requests in the window before the breaker may open, default 20.
+	MinRequests int
+	// FailureRatio opens the breaker when failures / requests in the window reaches it, default 0.5.
+	FailureRatio float64
+	// OpenTimeout is how long the breaker stays open before it becomes half-open, default 5s.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe requests allowed in half-open state, which must all succeed
+	// to close the breaker, default 1.
+	HalfOpenRequests int
+	// IsFailure classifies whether the error of a request is a failure, default is any error.
+	// Requests which are neither failures nor ignored are successes.
+	IsFailure func(err error) bool
+	// IsIgnored classifies whether the error of a request is ignored, i.e. it neither counts as a success
+	// nor as a failure, default is context.Canceled, which is usually caused by the caller rather than
+	// the dependency. It takes precedence over IsFailure.
+	IsIgnored func(err error) bool
+	// OnStateChange is called when the state changes, e.g. for logging. It is called synchronously
+	// without holding the lock of the breaker.
+	OnStateChange func(from, to State)
+	// Clock measures the window and timeouts, default is the Clock of go-future.
+	Clock future.Clock
+}
+
+// Breaker is a circuit breaker for async calls. When a dependency is down, requests fail immediately
+// with ErrCircuitOpen instead of waiting for a timeout, until the dependency recovers.
+//
+//	b := breaker.New(breaker.Policy{})
+//	f := breaker.Do(ctx, b, func(ctx context.Context) (*Response, error) {
+//	    return client.Call(ctx, req)
+//	})
+type Breaker struct {
+	policy    Policy
+	bucketDur time.Duration
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	openedAt   time.Time
+	buckets    []bucket
+	epoch      int64 // index of the latest bucket since creation
+	created    time.Time
+	probes     int // probes in flight in half-open state
+	succeeded  int // succeeded probes in half-open state
+}
+
+type bucket struct {
+	requests int
+	failures int
+}
+
+// New creates a closed Breaker with the given policy.
+func New(policy Policy) *Breaker {
+	if policy.Window <= 0 {
+		policy.Window = 10 * time.Second
+	}
+	if policy.Buckets <= 0 {
+		policy.Buckets = 10
+	}
+	if policy.MinRequests <= 0 {
+		policy.MinRequests = 20
+	}
+	if policy.FailureRatio <= 0 {
+		policy.FailureRatio = 0.5
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = 5 * time.Second
+	}
+	if policy.HalfOpenRequests <= 0 {
+		policy.HalfOpenRequests = 1
+	}
+	if policy.IsFailure == nil {
+		policy.IsFailure = func(err error) bool {
+			return err != nil
+		}
+	}
+	if policy.IsIgnored == nil {
+		policy.IsIgnored = func(err error) bool {
+			return errors.Is(err, context.Canceled)
+		}
+	}
+	if policy.Clock == nil {
+		policy.Clock = future.DefaultClock()
+	}
+	bucketDur := policy.Window / time.Duration(policy.Buckets)
+	if bucketDur <= 0 {
+		bucketDur = 1
+	}
+	return &Breaker{
+		policy:    policy,
+		bucketDur: bucketDur,
+		buckets:   make([]bucket, policy.Buckets),
+		created:   policy.Clock.Now(),
+	}
+}
+
+// Do calls fn like future.CtxAsync if b allows the request, and records its result in b.
+// Otherwise, fn is not called and the returned Future fails with ErrCircuitOpen.
+func Do[T any](ctx context.Context, b *Breaker, fn func(ctx context.Context) (T, error)) *future.Future[T] {
+	done, err := b.Allow()
+	if err != nil {
+		var zero T
+		return future.Done2(zero, err)
+	}
+	f := future.CtxAsync(ctx, fn)
+	f.Subscribe(func(_ T, err error) {
+		done(err)
+	})
+	return f
+}
+
+// State returns the current state of b.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	from := b.state
+	to := b.refresh(b.policy.Clock.Now())
+	b.mu.Unlock()
+	b.notify(from, to)
+	return to
+}
+
+// Allow checks whether a request is allowed. If allowed, done must be called with the error of the request
+// once it finishes, otherwise ErrCircuitOpen is returned. It is the low-level API used by Do.
+func (b *Breaker) Allow() (done func(err error), err error) {
+	b.mu.Lock()
+	from := b.state
+	to := b.refresh(b.policy.Clock.Now())
+	switch to {
+	case StateOpen:
+		b.mu.Unlock()
+		b.notify(from, to)
+		return nil, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.probes+b.succeeded >= b.policy.HalfOpenRequests {
+			b.mu.Unlock()
+			b.notify(from, to)
+			return nil, ErrCircuitOpen
+		}
+		b.probes++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+	b.notify(from, to)
+
+	return func(err error) {
+		b.record(generation, b.classify(err))
+	}, nil
+}
+
+type outcome int
+
+const (
+	success outcome = iota
+	failure
+	ignored
+)
+
+func (b *Breaker) classify(err error) outcome {
+	switch {
+	case b.policy.IsIgnored(err):
+		return ignored
+	case b.policy.IsFailure(err):
+		return failure
+	default:
+		return success
+	}
+}
+
+func (b *Breaker) record(generation uint64, result outcome) {
+	b.mu.Lock()
+	now := b.policy.Clock.Now()
+	from := b.state
+	mid := b.refresh(now)
+	to := mid
+	if generation != b.generation {
+		// the request was allowed in a previous state, its result is outdated
+		b.mu.Unlock()
+		b.notify(from, mid)
+		return
+	}
+	switch mid {
+	case StateClosed:
+		if result == ignored {
+			break
+		}
+		cur := b.rotate(now)
+		cur.requests++
+		if result == failure {
+			cur.failures++
+		}
+		requests, failures := 0, 0
+		for _, bk := range b.buckets {
+			requests += bk.requests
+			failures += bk.failures
+		}
+		if result == failure && requests >= b.policy.MinRequests && float64(failures) >= b.policy.FailureRatio*float64(requests) {
+			to = b.transit(StateOpen, now)
+		}
+	case StateHalfOpen:
+		// an ignored probe only releases its slot for another probe
+		b.probes--
+		switch result {
+		case failure:
+			to = b.transit(StateOpen, now)
+		case success:
+			b.succeeded++
+			if b.succeeded >= b.policy.HalfOpenRequests {
+				to = b.transit(StateClosed, now)
+			}
+		}
+	}
+	b.mu.Unlock()
+	b.notify(from, mid)
+	b.notify(mid, to)
+}
+
+// refresh moves an open breaker to half-open if the open timeout elapsed, must be called with b.mu held.
+func (b *Breaker) refresh(now time.Time) State {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.policy.OpenTimeout {
+		return b.transit(StateHalfOpen, now)
+	}
+	return b.state
+}
+
+// transit moves the breaker to state and starts a new generation, must be called with b.mu held.
+func (b *Breaker) transit(state State, now time.Time) State {
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.succeeded = 0
+	switch state {
+	case StateOpen:
+		b.openedAt = now
+	case StateClosed:
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+	}
+	return state
+}
+
+// rotate clears the buckets expired since the last rotation and returns the current bucket,
+// must be called with b.mu held.
+func (b *Breaker) rotate(now time.Time) *bucket {
+	epoch := int64(now.Sub(b.created) / b.bucketDur)
+	n := int64(len(b.buckets))
+	if epoch-b.epoch >= n {
+		for i := range b.buckets {
+			b.buckets[i] = bucket{}
+		}
+	} else {
+		for e := b.epoch + 1; e <= epoch; e++ {
+			b.buckets[e%n] = bucket{}
+		}
+	}
+	if epoch > b.epoch {
+		b.epoch = epoch
+	}
+	return &b.buckets[b.epoch%n]
+}
+
+func (b *Breaker) notify(from, to State) {
+	if from != to && b.policy.OnStateChange != nil {
+		b.policy.OnStateChange(from, to)
+	}
+}
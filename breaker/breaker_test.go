@@ -0,0 +1,137 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jizhuozhi/go-future/futuretest"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFail = errors.New("fail")
+
+func call(b *Breaker, err error) error {
+	_, e := Do(context.Background(), b, func(ctx context.Context) (int, error) {
+		return 1, err
+	}).Get()
+	return e
+}
+
+func TestBreakerTripAndRecover(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var mu sync.Mutex
+	var changes []State
+	b := New(Policy{
+		MinRequests:      4,
+		FailureRatio:     0.5,
+		OpenTimeout:      time.Second,
+		HalfOpenRequests: 2,
+		Clock:            clock,
+		OnStateChange: func(from, to State) {
+			mu.Lock()
+			changes = append(changes, to)
+			mu.Unlock()
+		},
+	})
+
+	assert.NoError(t, call(b, nil))
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrCircuitOpen, call(b, nil))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, StateClosed, b.State())
+
+	mu.Lock()
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateClosed}, changes)
+	mu.Unlock()
+}
+
+func TestBreakerHalfOpenFailure(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	b := New(Policy{MinRequests: 1, OpenTimeout: time.Second, Clock: clock})
+
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateOpen, b.State())
+	clock.Advance(time.Second)
+
+	done, err := b.Allow()
+	assert.NoError(t, err)
+	_, err = b.Allow()
+	assert.Equal(t, ErrCircuitOpen, err, "only one probe in half-open state")
+	done(errFail)
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreakerRollingWindow(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	b := New(Policy{Window: 10 * time.Second, Buckets: 10, MinRequests: 3, Clock: clock})
+
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, errFail, call(b, errFail))
+	clock.Advance(10 * time.Second)
+	// the failures above expired
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateClosed, b.State())
+	clock.Advance(5 * time.Second)
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBreakerOutdatedResult(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	b := New(Policy{MinRequests: 1, OpenTimeout: time.Second, Clock: clock})
+
+	done, err := b.Allow()
+	assert.NoError(t, err)
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateOpen, b.State())
+	clock.Advance(time.Second)
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, StateClosed, b.State())
+
+	// allowed before the breaker opened, ignored
+	done(errFail)
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerIsFailure(t *testing.T) {
+	b := New(Policy{MinRequests: 1})
+	assert.Equal(t, context.Canceled, call(b, context.Canceled))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, "half-open", StateHalfOpen.String())
+}
+
+func TestBreakerIgnored(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	b := New(Policy{MinRequests: 2, FailureRatio: 0.5, OpenTimeout: time.Second, Clock: clock})
+
+	// cancelled requests do not dilute the failure ratio
+	assert.NoError(t, call(b, nil))
+	assert.Equal(t, context.Canceled, call(b, context.Canceled))
+	assert.Equal(t, context.Canceled, call(b, context.Canceled))
+	assert.Equal(t, errFail, call(b, errFail))
+	assert.Equal(t, StateOpen, b.State())
+
+	// a cancelled probe neither closes nor reopens the breaker, but releases its slot
+	clock.Advance(time.Second)
+	done, err := b.Allow()
+	assert.NoError(t, err)
+	done(context.Canceled)
+	assert.Equal(t, StateHalfOpen, b.State())
+	done, err = b.Allow()
+	assert.NoError(t, err)
+	done(nil)
+	assert.Equal(t, StateClosed, b.State())
+}
@@ -0,0 +1,86 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+var ErrChanClosed = errors.New("channel closed")
+
+// FromChan returns a Future which is completed with the first value received from ch,
+// or fails with ErrChanClosed if ch is closed without any value.
+//
+// A goroutine waits for ch until a value is received or ch is closed.
+func FromChan[T any](ch <-chan T) *Future[T] {
+	s := &state[T]{}
+	go func() {
+		val, ok := <-ch
+		if !ok {
+			s.set(val, ErrChanClosed)
+			return
+		}
+		s.set(val, nil)
+	}()
+	return &Future[T]{state: s}
+}
+
+// FromResultChan is like FromChan, but the Future is completed with both the value and the error
+// of the first Result received from ch. It is the inverse of ToChan.
+func FromResultChan[T any](ch <-chan Result[T]) *Future[T] {
+	s := &state[T]{}
+	go func() {
+		res, ok := <-ch
+		if !ok {
+			s.set(res.Val, ErrChanClosed)
+			return
+		}
+		s.set(res.Val, res.Err)
+	}()
+	return &Future[T]{state: s}
+}
+
+// CollectChan returns a Future which is completed with all values received from ch when ch is closed.
+//
+// A goroutine receives values from ch until it is closed.
+func CollectChan[T any](ch <-chan T) *Future[[]T] {
+	s := &state[[]T]{}
+	go func() {
+		var vals []T
+		for val := range ch {
+			vals = append(vals, val)
+		}
+		s.set(vals, nil)
+	}()
+	return &Future[[]T]{state: s}
+}
+
+// ToChanCtx is like ToChan, but if ctx is done before f, a Result with ctx.Err() is sent instead.
+//
+// Like ToChan, exactly one Result is sent through the channel, which is then closed.
+func ToChanCtx[T any](ctx context.Context, f *Future[T]) <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	fdone := make(chan struct{})
+	var done uint32
+	send := func(val T, err error) {
+		if atomic.CompareAndSwapUint32(&done, 0, 1) {
+			ch <- Result[T]{Val: val, Err: err}
+			close(ch)
+		}
+	}
+	f.state.subscribe(func(val T, err error) {
+		send(val, err)
+		close(fdone)
+	})
+	if ctx.Done() != nil && !f.Done() {
+		go func() {
+			select {
+			case <-ctx.Done():
+				var zero T
+				send(zero, ctx.Err())
+			case <-fdone:
+			}
+		}()
+	}
+	return ch
+}
@@ -0,0 +1,81 @@
+package future
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromChan(t *testing.T) {
+	{
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		val, err := FromChan(ch).Get()
+		assert.Equal(t, 1, val)
+		assert.NoError(t, err)
+	}
+	{
+		ch := make(chan int)
+		close(ch)
+		_, err := FromChan(ch).Get()
+		assert.ErrorIs(t, err, ErrChanClosed)
+	}
+}
+
+func TestFromResultChan(t *testing.T) {
+	{
+		val, err := FromResultChan(ToChan(Done2(1, errFoo))).Get()
+		assert.Equal(t, 1, val)
+		assert.ErrorIs(t, err, errFoo)
+	}
+	{
+		ch := make(chan Result[int])
+		close(ch)
+		_, err := FromResultChan(ch).Get()
+		assert.ErrorIs(t, err, ErrChanClosed)
+	}
+}
+
+func TestCollectChan(t *testing.T) {
+	ch := make(chan int)
+	f := CollectChan(ch)
+	for i := 0; i < 3; i++ {
+		ch <- i
+	}
+	assert.False(t, f.Done())
+	close(ch)
+	vals, err := f.Get()
+	assert.Equal(t, []int{0, 1, 2}, vals)
+	assert.NoError(t, err)
+}
+
+func TestToChanCtx(t *testing.T) {
+	{
+		res := <-ToChanCtx(context.Background(), Done2(1, errFoo))
+		assert.Equal(t, 1, res.Val)
+		assert.Equal(t, errFoo, res.Err)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		p := NewPromise[int]()
+		ch := ToChanCtx(ctx, p.Future())
+		cancel()
+		res := <-ch
+		assert.ErrorIs(t, res.Err, context.Canceled)
+		_, ok := <-ch
+		assert.False(t, ok)
+		p.Set(1, nil)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p := NewPromise[int]()
+		ch := ToChanCtx(ctx, p.Future())
+		p.Set(1, nil)
+		res := <-ch
+		assert.Equal(t, 1, res.Val)
+		assert.NoError(t, res.Err)
+	}
+}
@@ -0,0 +1,58 @@
+package future
+
+import "time"
+
+// Clock defines an abstraction of time used by go-future, e.g. by Timeout and Until.
+//
+// By default, go-future uses the system clock (SystemClock), which is backed by the time package.
+// The clock can be replaced globally with SetClock, or per call with TimeoutWithClock and UntilWithClock,
+// so that simulated time can be used for tests and replay, see futuretest.Clock.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f.
+	// It returns a Timer that can be used to cancel the call using its Stop method.
+	//
+	// Implementations may call f in the goroutine driving the clock instead of its own goroutine,
+	// so f should not contain blocking operations.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the handle of a pending call created by Clock.AfterFunc.
+//
+// Stop prevents the call from firing. It returns true if the call stops the timer,
+// false if the timer has already fired or been stopped. *time.Timer implements Timer.
+type Timer interface {
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+var clock Clock = systemClock{}
+
+// SystemClock returns the Clock backed by the time package.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// DefaultClock returns the Clock used by go-future, which is SystemClock unless replaced by SetClock.
+func DefaultClock() Clock {
+	return clock
+}
+
+// SetClock replaces the Clock used by go-future.
+//
+// Passing nil to SetClock will panic.
+func SetClock(c Clock) {
+	if c == nil {
+		panic("clock is nil")
+	}
+	clock = c
+}
@@ -0,0 +1,24 @@
+package future
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	c := SystemClock()
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+
+	done := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() {
+		close(done)
+	})
+	<-done
+	assert.True(t, c.AfterFunc(time.Hour, func() {}).Stop())
+
+	assert.Panics(t, func() {
+		SetClock(nil)
+	})
+}
@@ -0,0 +1,50 @@
+package future
+
+import (
+	"context"
+)
+
+// FromContext returns a Future which is completed with ctx.Err() when ctx is done.
+//
+// If ctx is already done, the Future is completed immediately, and if ctx can never be cancelled
+// (i.e. ctx.Done() returns nil), the Future is never completed. Otherwise, a goroutine waits for ctx
+// until it is done, so ctx should be cancelled eventually to avoid leaking the goroutine.
+func FromContext(ctx context.Context) *Future[struct{}] {
+	s := &state[struct{}]{}
+	done := ctx.Done()
+	if done == nil {
+		return &Future[struct{}]{state: s}
+	}
+	select {
+	case <-done:
+		s.set(struct{}{}, ctx.Err())
+	default:
+		go func() {
+			<-done
+			s.set(struct{}{}, ctx.Err())
+		}()
+	}
+	return &Future[struct{}]{state: s}
+}
+
+// Context returns a context derived from parent, which is cancelled when the Future is done or parent is done.
+//
+// No goroutine is created, the context is cancelled by a callback subscribed to the Future.
+// If neither the Future nor parent is ever done, resources of the context are never released,
+// use WithFutureCancel to release them explicitly.
+func (f *Future[T]) Context(parent context.Context) context.Context {
+	ctx, _ := WithFutureCancel(parent, f)
+	return ctx
+}
+
+// WithFutureCancel is like context.WithCancel, but the returned context is also cancelled when f is done.
+//
+// It is useful to stop work that is only needed until f completes, e.g. cancel the losers of a race.
+// No goroutine is created, the context is cancelled by a callback subscribed to f.
+func WithFutureCancel[T any](ctx context.Context, f *Future[T]) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	f.state.subscribe(func(T, error) {
+		cancel()
+	})
+	return ctx, cancel
+}
@@ -0,0 +1,76 @@
+package future
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		f := FromContext(ctx)
+		assert.False(t, f.Done())
+		cancel()
+		_, err := f.Get()
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err := FromContext(ctx).Get()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		f := FromContext(ctx)
+		assert.True(t, f.Done())
+	}
+	{
+		f := FromContext(context.Background())
+		assert.False(t, f.Done())
+	}
+}
+
+func TestFutureContext(t *testing.T) {
+	{
+		p := NewPromise[int]()
+		ctx := p.Future().Context(context.WithValue(context.Background(), "foo", "bar"))
+		assert.Equal(t, "bar", ctx.Value("foo"))
+		assert.NoError(t, ctx.Err())
+		p.Set(1, nil)
+		<-ctx.Done()
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+	{
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := NewPromise[int]().Future().Context(parent)
+		cancel()
+		<-ctx.Done()
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+	{
+		ctx := Done(1).Context(context.Background())
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+}
+
+func TestWithFutureCancel(t *testing.T) {
+	{
+		p := NewPromise[int]()
+		ctx, cancel := WithFutureCancel(context.Background(), p.Future())
+		defer cancel()
+		assert.NoError(t, ctx.Err())
+		p.Set(0, errFoo)
+		<-ctx.Done()
+	}
+	{
+		ctx, cancel := WithFutureCancel(context.Background(), NewPromise[int]().Future())
+		cancel()
+		<-ctx.Done()
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	}
+}
@@ -15,6 +15,7 @@ var (
 	ErrDAGNodeNotInput    = errors.New("DAG node is not input")
 	ErrDAGNodeNotRunnable = errors.New("DAG node is not runnable")
 	ErrDAGNodeNotExecuted = errors.New("DAG node is not executed")
+	ErrDAGNodeNotExisted  = errors.New("DAG node is not existed")
 
 	ErrDAGFrozen     = errors.New("DAG node is frozen")
 	ErrDAGNotFrozen  = errors.New("DAG node is not frozen")
@@ -35,6 +36,9 @@ type NodeSpec struct {
 	run   NodeFunc
 	input bool
 
+	// priority is used to submit the node when the executor supports priorities
+	priority int
+
 	// subgraph if not nil, this node presents a subgraph
 	subgraph              *DAG
 	subgraphInputMapping  func(map[NodeID]any) map[NodeID]any
@@ -114,6 +118,24 @@ func (d *DAG) AddSubgraph(id NodeID, deps []NodeID, subgraph *DAG, inputMapping
 	return nil
 }
 
+// SetPriority sets the priority used to submit the node, larger runs earlier. Default priority is 0.
+//
+// The priority only takes effect if the executor of go-future supports priorities (e.g. executors.PriorityExecutor),
+// which allows critical nodes to jump the queue of a saturated executor.
+//
+// Will return an error if the DAG is frozen or the node does not exist.
+func (d *DAG) SetPriority(id NodeID, priority int) error {
+	if d.frozen {
+		return ErrDAGFrozen
+	}
+	node, exists := d.nodes[id]
+	if !exists {
+		return ErrDAGNodeNotExisted
+	}
+	node.priority = priority
+	return nil
+}
+
 // Freeze verifies that the DAG structure is complete and acyclic,
 // and marks the DAG as immutable for future instantiations.
 //
@@ -204,6 +226,7 @@ func (d *DAG) Instantiate(inputs map[NodeID]any, wrappers ...NodeFuncWrapper) (*
 		return nil, ErrDAGNotFrozen
 	}
 
+	inst := &DAGInstance{spec: d, wrappers: wrappers}
 	nodes := make(map[NodeID]*NodeInstance)
 	children := make(map[NodeID][]NodeID)
 	for id, spec := range d.nodes {
@@ -244,6 +267,7 @@ func (d *DAG) Instantiate(inputs map[NodeID]any, wrappers ...NodeFuncWrapper) (*
 				if err != nil {
 					return nil, err
 				}
+				subInstance.clock = inst.clock
 				node.subgraph = subInstance
 				res, err := subInstance.Run(ctx)
 				if err != nil {
@@ -266,11 +290,8 @@ func (d *DAG) Instantiate(inputs map[NodeID]any, wrappers ...NodeFuncWrapper) (*
 		node.children = children[id]
 	}
 
-	return &DAGInstance{
-		spec:     d,
-		nodes:    nodes,
-		wrappers: wrappers,
-	}, nil
+	inst.nodes = nodes
+	return inst, nil
 }
 
 // NodeInstance represents a runtime execution context for a single DAG node
@@ -292,6 +313,7 @@ type NodeInstance struct {
 func (n *NodeInstance) ID() NodeID                  { return n.spec.id }
 func (n *NodeInstance) Deps() []NodeID              { return n.spec.deps }
 func (n *NodeInstance) Input() bool                 { return n.spec.input }
+func (n *NodeInstance) Priority() int               { return n.spec.priority }
 func (n *NodeInstance) Subgraph() *DAGInstance      { return n.subgraph }
 func (n *NodeInstance) Future() *future.Future[any] { return n.future }
 func (n *NodeInstance) Duration() time.Duration     { return n.duration }
@@ -301,6 +323,20 @@ type DAGInstance struct {
 	spec     *DAG
 	nodes    map[NodeID]*NodeInstance
 	wrappers []NodeFuncWrapper
+	clock    future.Clock
+}
+
+// SetClock sets the Clock used to measure node durations, e.g. to use simulated time for tests and replay.
+// It must be called before running the instance. By default, the Clock of go-future is used.
+func (d *DAGInstance) SetClock(c future.Clock) {
+	d.clock = c
+}
+
+func (d *DAGInstance) now() time.Time {
+	if d.clock != nil {
+		return d.clock.Now()
+	}
+	return future.DefaultClock().Now()
 }
 
 // Run runs the DAG instance and returns the final values or error
@@ -368,8 +404,8 @@ func (d *DAGInstance) schedule(ctx context.Context, id NodeID) {
 	for i := len(d.wrappers) - 1; i >= 0; i-- {
 		run = d.wrappers[i](node, run)
 	}
-	node.start = time.Now()
-	future.CtxAsync(ctx, func(ctx context.Context) (any, error) {
+	node.start = d.now()
+	future.CtxAsyncPriority(ctx, node.spec.priority, func(ctx context.Context) (any, error) {
 		deps := make(map[NodeID]any)
 		for _, depid := range node.spec.deps {
 			v, err := d.nodes[depid].future.Get()
@@ -380,7 +416,7 @@ func (d *DAGInstance) schedule(ctx context.Context, id NodeID) {
 			deps[depid] = v
 		}
 		val, err := run(ctx, deps)
-		node.duration = time.Since(node.start)
+		node.duration = d.now().Sub(node.start)
 		if err != nil {
 			return nil, err
 		}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future/futuretest"
 )
 
 func TestDAG_SimpleExecution(t *testing.T) {
@@ -191,6 +193,54 @@ func TestDAG_NodeDuration(t *testing.T) {
 	assert.GreaterOrEqual(t, dur.Milliseconds(), int64(15))
 }
 
+func TestDAG_Priority(t *testing.T) {
+	dag := NewDAG()
+	assert.NoError(t, dag.AddInput("A"))
+	assert.NoError(t, dag.AddNode("B", []NodeID{"A"}, func(ctx context.Context, deps map[NodeID]any) (any, error) {
+		return deps["A"].(int) + 1, nil
+	}))
+	assert.NoError(t, dag.SetPriority("B", 10))
+	assert.ErrorIs(t, dag.SetPriority("C", 10), ErrDAGNodeNotExisted)
+	assert.NoError(t, dag.Freeze())
+	assert.ErrorIs(t, dag.SetPriority("B", 1), ErrDAGFrozen)
+
+	inst, err := dag.Instantiate(map[NodeID]any{"A": 1})
+	assert.NoError(t, err)
+	res, err := inst.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res["B"])
+	assert.Equal(t, 0, inst.Nodes()["A"].Priority())
+	assert.Equal(t, 10, inst.Nodes()["B"].Priority())
+}
+
+func TestDAG_NodeDurationWithClock(t *testing.T) {
+	clock := futuretest.NewClock(time.Now())
+
+	sub := NewDAG()
+	assert.NoError(t, sub.AddNode("X", nil, func(ctx context.Context, _ map[NodeID]any) (any, error) {
+		clock.Advance(3 * time.Second)
+		return "x", nil
+	}))
+	assert.NoError(t, sub.Freeze())
+
+	dag := NewDAG()
+	assert.NoError(t, dag.AddNode("A", nil, func(ctx context.Context, _ map[NodeID]any) (any, error) {
+		clock.Advance(5 * time.Second)
+		return "a", nil
+	}))
+	assert.NoError(t, dag.AddSubgraph("S", []NodeID{"A"}, sub, nil, nil))
+	assert.NoError(t, dag.Freeze())
+
+	inst, err := dag.Instantiate(nil)
+	assert.NoError(t, err)
+	inst.SetClock(clock)
+	_, err = inst.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, inst.Nodes()["A"].Duration())
+	assert.Equal(t, 3*time.Second, inst.Nodes()["S"].Duration())
+	assert.Equal(t, 3*time.Second, inst.Nodes()["S"].Subgraph().Nodes()["X"].Duration())
+}
+
 func TestDAG_ForwardReference(t *testing.T) {
 	dag := NewDAG()
 	// Forward reference: B depends on A, but A is added later
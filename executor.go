@@ -18,6 +18,15 @@ import "github.com/jizhuozhi/go-future/executors"
 // Most cases do NOT require changing the executor. Replacing the default executor can be useful
 // to limit concurrency, reuse goroutines, or reduce GC pressure.
 //
+// Executors may reject tasks by implementing executors.TrySubmitter, in which case the Future returned by
+// Async, Submit and their context variants fails with the rejection error. For example, to stop accepting
+// async work on SIGTERM and wait for in-flight tasks:
+//
+//	e := executors.Graceful(executors.GoExecutor{})
+//	SetExecutor(e)
+//	...
+//	_ = e.Shutdown(ctx) // later Async calls fail with executors.ErrExecutorClosed
+//
 // Caution:
 //   - For RPC tasks or other potentially blocking operations, using a pooled executor may
 //     cause task queuing and negative performance impact. Only override the executor if you
@@ -1,6 +1,7 @@
 package future
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +10,7 @@ import (
 )
 
 func TestSetExecutor(t *testing.T) {
+	defer SetExecutor(executor)
 	counter := 0
 	SetExecutor(executors.ExecutorFunc(func(f func()) {
 		counter++
@@ -27,3 +29,81 @@ func TestSetExecutor(t *testing.T) {
 		SetExecutor(nil)
 	})
 }
+
+func TestSubmitExecutorClosed(t *testing.T) {
+	e := executors.Graceful(executors.GoExecutor{})
+	assert.NoError(t, e.Shutdown(context.Background()))
+
+	val, err := Submit(e, func() (int, error) {
+		return 1, nil
+	}).Get()
+	assert.Equal(t, 0, val)
+	assert.ErrorIs(t, err, executors.ErrExecutorClosed)
+
+	_, err = CtxSubmit(context.Background(), e, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Get()
+	assert.ErrorIs(t, err, executors.ErrExecutorClosed)
+}
+
+func TestSubmitPriority(t *testing.T) {
+	e := executors.Priority(executors.GoExecutor{}, 1, 0)
+
+	release := make(chan struct{})
+	blocker := Submit(e, func() (int, error) {
+		<-release
+		return 0, nil
+	})
+
+	var order []int
+	low := SubmitPriority(e, 1, func() (int, error) {
+		order = append(order, 1)
+		return 1, nil
+	})
+	high := CtxSubmitPriority(context.Background(), e, 2, func(ctx context.Context) (int, error) {
+		order = append(order, 2)
+		return 2, nil
+	})
+	close(release)
+
+	vals, err := AllOf(blocker, low, high).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, vals)
+	assert.Equal(t, []int{2, 1}, order)
+}
+
+func TestAsyncPriority(t *testing.T) {
+	val, err := AsyncPriority(1, func() (int, error) {
+		return 1, nil
+	}).Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+
+	val, err = CtxAsyncPriority(context.Background(), 1, func(ctx context.Context) (int, error) {
+		return 2, nil
+	}).Get()
+	assert.Equal(t, 2, val)
+	assert.NoError(t, err)
+}
+
+func TestCtxSubmitContextCancelled(t *testing.T) {
+	e := executors.Limited(executors.GoExecutor{}, 1)
+
+	release := make(chan struct{})
+	f := Submit(e, func() (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := CtxSubmit(ctx, e, func(ctx context.Context) (int, error) {
+		return 2, nil
+	}).Get()
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	val, err := f.Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+}
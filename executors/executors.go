@@ -1,5 +1,52 @@
 package executors
 
+import (
+	"context"
+)
+
+// Executor is the minimal task execution abstraction shared by all executors in this package.
+//
+// It is intentionally identical to future.Executor, so that any value of this package
+// can be passed to future.SetExecutor or future.Submit directly.
+type Executor interface {
+	Submit(func())
+}
+
+// TrySubmitter is implemented by executors that may reject tasks, e.g. after being shut down.
+//
+// TrySubmit returns a non-nil error if and only if the task will never be executed.
+type TrySubmitter interface {
+	TrySubmit(func()) error
+}
+
+// TrySubmit submits f to e, using e.TrySubmit if e implements TrySubmitter.
+// For plain executors it always returns nil.
+func TrySubmit(e Executor, f func()) error {
+	if ts, ok := e.(TrySubmitter); ok {
+		return ts.TrySubmit(f)
+	}
+	e.Submit(f)
+	return nil
+}
+
+// ContextSubmitter is implemented by executors that may wait before accepting a task, e.g. for a rate limit
+// or a concurrency limit, so that the waiting can be cancelled by ctx.
+//
+// TrySubmitContext has the same rejection semantics as TrySubmitter.TrySubmit, and returns ctx.Err()
+// if ctx is done before the task is accepted.
+type ContextSubmitter interface {
+	TrySubmitContext(ctx context.Context, f func()) error
+}
+
+// TrySubmitContext submits f to e, using e.TrySubmitContext if e implements ContextSubmitter.
+// Otherwise, it falls back to TrySubmit.
+func TrySubmitContext(ctx context.Context, e Executor, f func()) error {
+	if cs, ok := e.(ContextSubmitter); ok {
+		return cs.TrySubmitContext(ctx, f)
+	}
+	return TrySubmit(e, f)
+}
+
 type GoExecutor struct{}
 
 func (GoExecutor) Submit(f func()) {
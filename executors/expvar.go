@@ -0,0 +1,67 @@
+package executors
+
+import (
+	"expvar"
+	"time"
+)
+
+// ExpvarObserver is an Observer exporting the metrics of an InstrumentedExecutor with expvar.
+//
+// The following variables are published in an expvar.Map:
+//   - submitted, rejected, started, finished, panics: counters of tasks
+//   - wait_ns, run_ns: total queue wait time and run time of tasks in nanoseconds
+//   - queued, active: gauges of tasks not started yet and tasks running
+//
+// Gauges are derived from the counters, they are approximate while tasks are being submitted concurrently.
+type ExpvarObserver struct {
+	submitted expvar.Int
+	rejected  expvar.Int
+	started   expvar.Int
+	finished  expvar.Int
+	panics    expvar.Int
+	waitNanos expvar.Int
+	runNanos  expvar.Int
+}
+
+// NewExpvarObserver creates an ExpvarObserver published as an expvar.Map with the given name.
+//
+// Like expvar.Publish, it panics if the name is already registered.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	o := &ExpvarObserver{}
+	m := expvar.NewMap(name)
+	m.Set("submitted", &o.submitted)
+	m.Set("rejected", &o.rejected)
+	m.Set("started", &o.started)
+	m.Set("finished", &o.finished)
+	m.Set("panics", &o.panics)
+	m.Set("wait_ns", &o.waitNanos)
+	m.Set("run_ns", &o.runNanos)
+	m.Set("queued", expvar.Func(func() any {
+		return o.submitted.Value() - o.rejected.Value() - o.started.Value()
+	}))
+	m.Set("active", expvar.Func(func() any {
+		return o.started.Value() - o.finished.Value()
+	}))
+	return o
+}
+
+func (o *ExpvarObserver) OnSubmit(int64) {
+	o.submitted.Add(1)
+}
+
+func (o *ExpvarObserver) OnReject(error) {
+	o.rejected.Add(1)
+}
+
+func (o *ExpvarObserver) OnStart(wait time.Duration, _ int64) {
+	o.waitNanos.Add(int64(wait))
+	o.started.Add(1)
+}
+
+func (o *ExpvarObserver) OnFinish(run time.Duration, _ int64, panicked bool) {
+	o.runNanos.Add(int64(run))
+	o.finished.Add(1)
+	if panicked {
+		o.panics.Add(1)
+	}
+}
@@ -0,0 +1,137 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrExecutorClosed = errors.New("executor closed")
+
+// GracefulExecutor wraps an Executor with a shutdown lifecycle.
+//
+// It tracks all in-flight tasks submitted through it, so that Drain can wait for them to finish,
+// and after Shutdown it rejects new tasks with ErrExecutorClosed. Futures created by future.Submit
+// and future.Async on a GracefulExecutor fail with ErrExecutorClosed instead of never completing.
+type GracefulExecutor struct {
+	inner Executor
+
+	mu     sync.Mutex
+	closed bool
+	active int
+	idle   chan struct{} // closed when active drops to zero, nil if nobody is waiting
+}
+
+// Graceful creates a GracefulExecutor running tasks on inner.
+//
+// Passing nil will panic.
+func Graceful(inner Executor) *GracefulExecutor {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	return &GracefulExecutor{inner: inner}
+}
+
+// Submit submits f to the underlying executor. The task is dropped if the executor is closed,
+// use TrySubmit to observe the rejection.
+func (e *GracefulExecutor) Submit(f func()) {
+	_ = e.TrySubmit(f)
+}
+
+// TrySubmit submits f to the underlying executor, or returns ErrExecutorClosed if the executor is closed.
+func (e *GracefulExecutor) TrySubmit(f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmit(e.inner, f)
+	})
+}
+
+// TrySubmitContext is like TrySubmit, but passes ctx to the underlying executor if it supports it.
+func (e *GracefulExecutor) TrySubmitContext(ctx context.Context, f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmitContext(ctx, e.inner, f)
+	})
+}
+
+// TrySubmitPriority is like TrySubmit, but keeps the priority if the underlying executor supports it.
+func (e *GracefulExecutor) TrySubmitPriority(priority int, f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmitPriority(e.inner, priority, f)
+	})
+}
+
+func (e *GracefulExecutor) trySubmit(f func(), submit func(func()) error) error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return ErrExecutorClosed
+	}
+	e.active++
+	e.mu.Unlock()
+
+	err := submit(func() {
+		defer e.done()
+		f()
+	})
+	if err != nil {
+		e.done()
+	}
+	return err
+}
+
+// Active returns the number of tasks which are submitted but not finished yet.
+func (e *GracefulExecutor) Active() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.active
+}
+
+// Closed returns true if Shutdown has been called.
+func (e *GracefulExecutor) Closed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closed
+}
+
+// Drain waits until all in-flight tasks are finished or ctx is done, without closing the executor.
+//
+// Tasks submitted concurrently with Drain are waited too, so Drain may never return under
+// continuous load. Use Shutdown to stop accepting tasks first.
+func (e *GracefulExecutor) Drain(ctx context.Context) error {
+	e.mu.Lock()
+	if e.active == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	if e.idle == nil {
+		e.idle = make(chan struct{})
+	}
+	idle := e.idle
+	e.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting new tasks and waits until all in-flight tasks are finished or ctx is done.
+//
+// It is safe to call Shutdown multiple times, all calls wait for the in-flight tasks.
+func (e *GracefulExecutor) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	e.closed = true
+	e.mu.Unlock()
+	return e.Drain(ctx)
+}
+
+func (e *GracefulExecutor) done() {
+	e.mu.Lock()
+	e.active--
+	if e.active == 0 && e.idle != nil {
+		close(e.idle)
+		e.idle = nil
+	}
+	e.mu.Unlock()
+}
@@ -0,0 +1,67 @@
+package executors
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	e := Graceful(GoExecutor{})
+
+	var counter int32
+	release := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, e.TrySubmit(func() {
+			<-release
+			atomic.AddInt32(&counter, 1)
+		}))
+	}
+	assert.Equal(t, 10, e.Active())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.Shutdown(ctx), context.DeadlineExceeded)
+	assert.True(t, e.Closed())
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+
+	close(release)
+	assert.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, int32(10), atomic.LoadInt32(&counter))
+	assert.Equal(t, 0, e.Active())
+}
+
+func TestGracefulDrain(t *testing.T) {
+	e := Graceful(GoExecutor{})
+	assert.NoError(t, e.Drain(context.Background()))
+
+	var counter int32
+	for i := 0; i < 10; i++ {
+		e.Submit(func() {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&counter, 1)
+		})
+	}
+	assert.NoError(t, e.Drain(context.Background()))
+	assert.Equal(t, int32(10), atomic.LoadInt32(&counter))
+	assert.False(t, e.Closed())
+	assert.NoError(t, e.TrySubmit(func() {}))
+}
+
+func TestGracefulInnerRejected(t *testing.T) {
+	inner := Graceful(GoExecutor{})
+	assert.NoError(t, inner.Shutdown(context.Background()))
+
+	e := Graceful(inner)
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+	assert.Equal(t, 0, e.Active())
+}
+
+func TestGracefulNil(t *testing.T) {
+	assert.Panics(t, func() {
+		Graceful(nil)
+	})
+}
@@ -0,0 +1,103 @@
+package executors
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Observer receives the events of tasks executed by an InstrumentedExecutor.
+//
+// Methods are called synchronously in the goroutines submitting and running tasks,
+// they should be cheap and must not block.
+type Observer interface {
+	// OnSubmit is called when a task is submitted, with the number of tasks submitted but not started yet.
+	OnSubmit(queued int64)
+	// OnReject is called when a task is rejected by the inner executor.
+	OnReject(err error)
+	// OnStart is called when a task starts, with the time it waited since submitted
+	// and the number of running tasks including itself.
+	OnStart(wait time.Duration, active int64)
+	// OnFinish is called when a task finishes, with the time it ran, the number of running tasks
+	// excluding itself and whether it panicked.
+	OnFinish(run time.Duration, active int64, panicked bool)
+}
+
+// InstrumentedExecutor reports the events of tasks executed by the inner executor to an Observer,
+// so that a saturated executor can be detected by its queue depth, queue wait time and active tasks.
+type InstrumentedExecutor struct {
+	inner    Executor
+	observer Observer
+
+	queued int64
+	active int64
+}
+
+// Instrumented creates an InstrumentedExecutor reporting tasks executed by inner to observer.
+//
+// Passing nil executor or nil observer will panic.
+func Instrumented(inner Executor, observer Observer) *InstrumentedExecutor {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	if observer == nil {
+		panic("observer is nil")
+	}
+	return &InstrumentedExecutor{inner: inner, observer: observer}
+}
+
+func (e *InstrumentedExecutor) Submit(f func()) {
+	_ = e.TrySubmit(f)
+}
+
+func (e *InstrumentedExecutor) TrySubmit(f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmit(e.inner, f)
+	})
+}
+
+// TrySubmitContext is like TrySubmit, but passes ctx to the underlying executor if it supports it.
+func (e *InstrumentedExecutor) TrySubmitContext(ctx context.Context, f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmitContext(ctx, e.inner, f)
+	})
+}
+
+// TrySubmitPriority is like TrySubmit, but keeps the priority if the underlying executor supports it.
+func (e *InstrumentedExecutor) TrySubmitPriority(priority int, f func()) error {
+	return e.trySubmit(f, func(f func()) error {
+		return TrySubmitPriority(e.inner, priority, f)
+	})
+}
+
+// Queued returns the number of tasks submitted but not started yet.
+func (e *InstrumentedExecutor) Queued() int64 {
+	return atomic.LoadInt64(&e.queued)
+}
+
+// Active returns the number of running tasks.
+func (e *InstrumentedExecutor) Active() int64 {
+	return atomic.LoadInt64(&e.active)
+}
+
+func (e *InstrumentedExecutor) trySubmit(f func(), submit func(func()) error) error {
+	submitted := time.Now()
+	e.observer.OnSubmit(atomic.AddInt64(&e.queued, 1))
+	err := submit(func() {
+		atomic.AddInt64(&e.queued, -1)
+		started := time.Now()
+		e.observer.OnStart(started.Sub(submitted), atomic.AddInt64(&e.active, 1))
+
+		panicked := true
+		defer func() {
+			e.observer.OnFinish(time.Since(started), atomic.AddInt64(&e.active, -1), panicked)
+		}()
+		f()
+		panicked = false
+	})
+	if err != nil {
+		atomic.AddInt64(&e.queued, -1)
+		e.observer.OnReject(err)
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package executors
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordObserver struct {
+	mu        sync.Mutex
+	submitted int
+	rejected  int
+	started   int
+	finished  int
+	panics    int
+	maxActive int64
+	wait      time.Duration
+	run       time.Duration
+}
+
+func (o *recordObserver) OnSubmit(queued int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.submitted++
+}
+
+func (o *recordObserver) OnReject(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rejected++
+}
+
+func (o *recordObserver) OnStart(wait time.Duration, active int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.started++
+	o.wait += wait
+	if active > o.maxActive {
+		o.maxActive = active
+	}
+}
+
+func (o *recordObserver) OnFinish(run time.Duration, active int64, panicked bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.finished++
+	o.run += run
+	if panicked {
+		o.panics++
+	}
+}
+
+func TestInstrumented(t *testing.T) {
+	o := &recordObserver{}
+	e := Instrumented(Limited(GoExecutor{}, 2), o)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		assert.NoError(t, e.TrySubmitContext(context.Background(), func() {
+			defer wg.Done()
+			time.Sleep(time.Millisecond)
+		}))
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool { return e.Active() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), e.Queued())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Equal(t, 10, o.submitted)
+	assert.Equal(t, 10, o.started)
+	assert.Equal(t, 10, o.finished)
+	assert.Equal(t, 0, o.panics)
+	assert.LessOrEqual(t, o.maxActive, int64(2))
+	assert.GreaterOrEqual(t, o.run, 10*time.Millisecond)
+	assert.Greater(t, o.wait, time.Duration(0))
+}
+
+func TestInstrumentedReject(t *testing.T) {
+	o := &recordObserver{}
+	inner := Graceful(GoExecutor{})
+	e := Instrumented(inner, o)
+
+	done := make(chan struct{})
+	assert.NoError(t, e.TrySubmitPriority(0, func() {
+		close(done)
+	}))
+	<-done
+
+	assert.NoError(t, inner.Shutdown(context.Background()))
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+	assert.Equal(t, int64(0), e.Queued())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Equal(t, 2, o.submitted)
+	assert.Equal(t, 1, o.rejected)
+	assert.Equal(t, 1, o.started)
+}
+
+func TestInstrumentedPanic(t *testing.T) {
+	o := &recordObserver{}
+	done := make(chan struct{})
+	e := Instrumented(ExecutorFunc(func(f func()) {
+		go func() {
+			defer func() {
+				_ = recover()
+				close(done)
+			}()
+			f()
+		}()
+	}), o)
+
+	e.Submit(func() {
+		panic("panic")
+	})
+	<-done
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	assert.Equal(t, 1, o.finished)
+	assert.Equal(t, 1, o.panics)
+}
+
+func TestExpvarObserver(t *testing.T) {
+	// unique name, so that the test can be run with -count
+	name := fmt.Sprintf("executors_test_%d", time.Now().UnixNano())
+	e := Instrumented(GoExecutor{}, NewExpvarObserver(name))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		e.Submit(wg.Done)
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool { return e.Active() == 0 }, time.Second, time.Millisecond)
+
+	var vars map[string]int64
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &vars))
+	assert.Equal(t, int64(10), vars["submitted"])
+	assert.Equal(t, int64(10), vars["started"])
+	assert.Equal(t, int64(10), vars["finished"])
+	assert.Equal(t, int64(0), vars["panics"])
+	assert.Equal(t, int64(0), vars["queued"])
+
+	assert.Panics(t, func() {
+		NewExpvarObserver(name)
+	})
+}
+
+func TestInstrumentedInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		Instrumented(nil, &recordObserver{})
+	})
+	assert.Panics(t, func() {
+		Instrumented(GoExecutor{}, nil)
+	})
+}
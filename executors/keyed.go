@@ -0,0 +1,153 @@
+package executors
+
+import (
+	"sync"
+)
+
+// KeyedExecutor serializes tasks per key while tasks of different keys run in parallel on the inner executor.
+//
+// Each key with pending tasks owns a strand, which is a FIFO queue drained by a single task submitted to the
+// inner executor. A strand is removed as soon as its queue becomes empty, so memory is bounded by the number of
+// pending tasks and idle keys are never retained.
+//
+//	keyed := executors.Keyed[int64](executors.GoExecutor{})
+//	f := future.Submit(keyed.For(userID), func() (User, error) {
+//	    return updateUser(userID)
+//	})
+type KeyedExecutor[K comparable] struct {
+	inner Executor
+
+	mu      sync.Mutex
+	strands map[K]*strand
+}
+
+type strand struct {
+	tasks    []func()
+	starting chan struct{} // closed once the strand is started or rejected, nil after that
+	err      error         // the rejection error of the inner executor
+}
+
+// Keyed creates a KeyedExecutor running strands on inner.
+//
+// Passing nil will panic.
+func Keyed[K comparable](inner Executor) *KeyedExecutor[K] {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	return &KeyedExecutor[K]{inner: inner, strands: make(map[K]*strand)}
+}
+
+// For returns an Executor which runs tasks sequentially in submission order with all other tasks of the same key.
+func (k *KeyedExecutor[K]) For(key K) Executor {
+	return &keyedExecutor[K]{keyed: k, key: key}
+}
+
+// Len returns the number of keys which have pending or running tasks.
+func (k *KeyedExecutor[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.strands)
+}
+
+// submit enqueues f to the strand of key, and starts the strand on the inner executor if it is idle.
+//
+// If the inner executor rejects the strand, the strand is discarded, and the rejection error is returned
+// to every caller whose task was enqueued to it, since the callers enqueuing while the strand is starting
+// wait for the result of starting.
+func (k *KeyedExecutor[K]) submit(key K, f func()) error {
+	k.mu.Lock()
+	s, ok := k.strands[key]
+	if !ok {
+		s = &strand{starting: make(chan struct{})}
+		k.strands[key] = s
+	}
+	s.tasks = append(s.tasks, f)
+	starting := s.starting
+	k.mu.Unlock()
+
+	if ok {
+		if starting == nil {
+			return nil
+		}
+		<-starting
+		return s.err
+	}
+	err := TrySubmit(k.inner, func() { k.run(key, s) })
+	k.mu.Lock()
+	if err != nil {
+		k.discard(key, s, err)
+	}
+	k.started(s)
+	k.mu.Unlock()
+	return err
+}
+
+// started wakes up the callers waiting for the strand s to start, must be called with k.mu held.
+// It is called by run as well, since an inline inner executor runs the strand before TrySubmit returns,
+// and a task submitting to its own key must not wait for the strand running it.
+func (k *KeyedExecutor[K]) started(s *strand) {
+	if s.starting != nil {
+		close(s.starting)
+		s.starting = nil
+	}
+}
+
+// discard removes the strand s of key and drops its tasks after the inner executor rejected it,
+// must be called with k.mu held.
+func (k *KeyedExecutor[K]) discard(key K, s *strand, err error) {
+	if k.strands[key] == s {
+		delete(k.strands, key)
+	}
+	s.tasks = nil
+	s.err = err
+}
+
+func (k *KeyedExecutor[K]) run(key K, s *strand) {
+	for {
+		k.mu.Lock()
+		k.started(s)
+		if len(s.tasks) == 0 {
+			delete(k.strands, key)
+			k.mu.Unlock()
+			return
+		}
+		f := s.tasks[0]
+		s.tasks[0] = nil
+		s.tasks = s.tasks[1:]
+		k.mu.Unlock()
+
+		k.exec(key, s, f)
+	}
+}
+
+// exec runs f, and if f panics, restarts the strand on the inner executor before propagating the panic,
+// so that the remaining tasks of the key are not stuck forever. If the inner executor rejects the restart
+// (e.g. it is shut down), the strand is discarded with its remaining tasks, so that later tasks of the key
+// start a new strand.
+func (k *KeyedExecutor[K]) exec(key K, s *strand, f func()) {
+	normal := false
+	defer func() {
+		if !normal {
+			if err := TrySubmit(k.inner, func() { k.run(key, s) }); err != nil {
+				k.mu.Lock()
+				k.discard(key, s, err)
+				k.mu.Unlock()
+			}
+		}
+	}()
+	f()
+	normal = true
+}
+
+type keyedExecutor[K comparable] struct {
+	keyed *KeyedExecutor[K]
+	key   K
+}
+
+func (e *keyedExecutor[K]) Submit(f func()) {
+	_ = e.keyed.submit(e.key, f)
+}
+
+func (e *keyedExecutor[K]) TrySubmit(f func()) error {
+	return e.keyed.submit(e.key, f)
+}
@@ -0,0 +1,177 @@
+package executors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedSerialPerKey(t *testing.T) {
+	keyed := Keyed[int](GoExecutor{})
+
+	n := 100
+	results := make([][]int, 3)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		for key := 0; key < len(results); key++ {
+			i, key := i, key
+			wg.Add(1)
+			keyed.For(key).Submit(func() {
+				defer wg.Done()
+				// no lock here, tasks of the same key never run concurrently
+				results[key] = append(results[key], i)
+			})
+		}
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Len(t, result, n)
+		for i := range result {
+			assert.Equal(t, i, result[i])
+		}
+	}
+	assert.Eventually(t, func() bool { return keyed.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestKeyedParallelKeys(t *testing.T) {
+	keyed := Keyed[string](GoExecutor{})
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	keyed.For("a").Submit(func() {
+		started <- struct{}{}
+		<-release
+	})
+	keyed.For("b").Submit(func() {
+		started <- struct{}{}
+		<-release
+	})
+	<-started
+	<-started
+	assert.Equal(t, 2, keyed.Len())
+	close(release)
+	assert.Eventually(t, func() bool { return keyed.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestKeyedPanic(t *testing.T) {
+	keyed := Keyed[int](ExecutorFunc(func(f func()) {
+		go func() {
+			defer func() {
+				_ = recover()
+			}()
+			f()
+		}()
+	}))
+
+	done := make(chan struct{})
+	keyed.For(1).Submit(func() {
+		panic("panic")
+	})
+	keyed.For(1).Submit(func() {
+		close(done)
+	})
+	<-done
+	assert.Eventually(t, func() bool { return keyed.Len() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestKeyedInnerRejected(t *testing.T) {
+	inner := Graceful(GoExecutor{})
+	assert.NoError(t, inner.Shutdown(context.Background()))
+
+	keyed := Keyed[int](inner)
+	err := keyed.For(1).(TrySubmitter).TrySubmit(func() {})
+	assert.ErrorIs(t, err, ErrExecutorClosed)
+	assert.Equal(t, 0, keyed.Len())
+}
+
+type rejectingExecutor struct {
+	accept  int32 // number of tasks to accept before rejecting
+	release chan struct{}
+}
+
+var errRejected = errors.New("rejected")
+
+func (e *rejectingExecutor) Submit(f func()) {
+	_ = e.TrySubmit(f)
+}
+
+func (e *rejectingExecutor) TrySubmit(f func()) error {
+	if atomic.AddInt32(&e.accept, -1) >= 0 {
+		go func() {
+			defer func() {
+				_ = recover()
+			}()
+			f()
+		}()
+		return nil
+	}
+	if e.release != nil {
+		<-e.release
+	}
+	return errRejected
+}
+
+func TestKeyedInnerRejectedConcurrently(t *testing.T) {
+	inner := &rejectingExecutor{release: make(chan struct{})}
+	keyed := Keyed[int](inner)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- keyed.For(1).(TrySubmitter).TrySubmit(func() {})
+	}()
+	assert.Eventually(t, func() bool { return keyed.Len() == 1 }, time.Second, time.Millisecond)
+	go func() {
+		errs <- keyed.For(1).(TrySubmitter).TrySubmit(func() {})
+	}()
+	assert.Eventually(t, func() bool {
+		keyed.mu.Lock()
+		defer keyed.mu.Unlock()
+		return len(keyed.strands[1].tasks) == 2
+	}, time.Second, time.Millisecond)
+
+	close(inner.release)
+	assert.ErrorIs(t, <-errs, errRejected)
+	assert.ErrorIs(t, <-errs, errRejected)
+	assert.Equal(t, 0, keyed.Len())
+}
+
+func TestKeyedPanicRestartRejected(t *testing.T) {
+	keyed := Keyed[int](&rejectingExecutor{accept: 1})
+
+	keyed.For(1).Submit(func() {
+		panic("panic")
+	})
+	assert.Eventually(t, func() bool { return keyed.Len() == 0 }, time.Second, time.Millisecond)
+	err := keyed.For(1).(TrySubmitter).TrySubmit(func() {})
+	assert.ErrorIs(t, err, errRejected)
+}
+
+func TestKeyedInlineSelfSubmit(t *testing.T) {
+	keyed := Keyed[int](ExecutorFunc(func(f func()) {
+		f()
+	}))
+	var order []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		keyed.For(1).Submit(func() {
+			order = append(order, 1)
+			keyed.For(1).Submit(func() {
+				order = append(order, 2)
+			})
+		})
+	}()
+	select {
+	case <-done:
+		assert.Equal(t, []int{1, 2}, order)
+		assert.Equal(t, 0, keyed.Len())
+	case <-time.After(time.Second):
+		t.Fatal("deadlocked submitting to its own key")
+	}
+}
@@ -0,0 +1,154 @@
+package executors
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimitedExecutor limits the concurrency of tasks running on the inner executor with a FIFO weighted semaphore.
+//
+// Submitting blocks the caller until enough capacity is available, which provides back-pressure to the
+// producer of tasks. When used through future.CtxSubmit, the waiting is cancelled by the context.
+type LimitedExecutor struct {
+	inner Executor
+	sem   *semaphore
+}
+
+// Limited creates a LimitedExecutor running at most maxConcurrent units of tasks concurrently on inner.
+//
+// Passing nil executor or non-positive maxConcurrent will panic.
+func Limited(inner Executor, maxConcurrent int) *LimitedExecutor {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	if maxConcurrent <= 0 {
+		panic("maxConcurrent must be positive")
+	}
+	return &LimitedExecutor{inner: inner, sem: newSemaphore(int64(maxConcurrent))}
+}
+
+// Submit submits f with weight 1, blocking until it is accepted.
+func (e *LimitedExecutor) Submit(f func()) {
+	_ = e.TrySubmitWeighted(context.Background(), 1, f)
+}
+
+// TrySubmit submits f with weight 1, blocking until it is accepted.
+func (e *LimitedExecutor) TrySubmit(f func()) error {
+	return e.TrySubmitWeighted(context.Background(), 1, f)
+}
+
+// TrySubmitContext submits f with weight 1, blocking until it is accepted or ctx is done.
+func (e *LimitedExecutor) TrySubmitContext(ctx context.Context, f func()) error {
+	return e.TrySubmitWeighted(ctx, 1, f)
+}
+
+// TrySubmitWeighted submits f which occupies weight units of the limit while running, blocking until
+// it is accepted or ctx is done. It returns ErrWeightExceeded if weight is larger than the limit,
+// and ErrInvalidWeight if weight is not positive.
+func (e *LimitedExecutor) TrySubmitWeighted(ctx context.Context, weight int, f func()) error {
+	n := int64(weight)
+	if err := e.sem.acquire(ctx, n); err != nil {
+		return err
+	}
+	err := TrySubmitContext(ctx, e.inner, func() {
+		defer e.sem.release(n)
+		f()
+	})
+	if err != nil {
+		e.sem.release(n)
+	}
+	return err
+}
+
+// RateLimitedExecutor limits the rate of tasks submitted to the inner executor with a token bucket.
+//
+// The bucket is refilled with rps tokens per second up to burst tokens, and each task consumes one token.
+// Submitting blocks the caller until a token is available, which provides back-pressure to the producer
+// of tasks. When used through future.CtxSubmit, the waiting is cancelled by the context.
+type RateLimitedExecutor struct {
+	inner Executor
+	rps   float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// RateLimited creates a RateLimitedExecutor submitting at most rps tasks per second with bursts of
+// at most burst tasks to inner. The bucket is full initially.
+//
+// Passing nil executor, non-positive rps or non-positive burst will panic.
+func RateLimited(inner Executor, rps float64, burst int) *RateLimitedExecutor {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	if rps <= 0 || math.IsNaN(rps) {
+		panic("rps must be positive")
+	}
+	if burst <= 0 {
+		panic("burst must be positive")
+	}
+	return &RateLimitedExecutor{
+		inner:  inner,
+		rps:    rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Submit submits f, blocking until a token is available.
+func (e *RateLimitedExecutor) Submit(f func()) {
+	_ = e.TrySubmitContext(context.Background(), f)
+}
+
+// TrySubmit submits f, blocking until a token is available.
+func (e *RateLimitedExecutor) TrySubmit(f func()) error {
+	return e.TrySubmitContext(context.Background(), f)
+}
+
+// TrySubmitContext submits f, blocking until a token is available or ctx is done.
+func (e *RateLimitedExecutor) TrySubmitContext(ctx context.Context, f func()) error {
+	if d := e.reserve(); d > 0 {
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			e.cancel()
+			return ctx.Err()
+		}
+	}
+	return TrySubmitContext(ctx, e.inner, f)
+}
+
+// reserve takes a token from the bucket, and returns how long to wait before the token is available.
+func (e *RateLimitedExecutor) reserve() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.tokens += now.Sub(e.last).Seconds() * e.rps
+	if e.tokens > e.burst {
+		e.tokens = e.burst
+	}
+	e.last = now
+	e.tokens--
+	if e.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-e.tokens / e.rps * float64(time.Second))
+}
+
+// cancel gives back a token reserved but not used.
+func (e *RateLimitedExecutor) cancel() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tokens++
+	if e.tokens > e.burst {
+		e.tokens = e.burst
+	}
+}
@@ -0,0 +1,159 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedConcurrency(t *testing.T) {
+	e := Limited(GoExecutor{}, 4)
+
+	var running, maxRunning int32
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		e.Submit(func() {
+			defer wg.Done()
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(4))
+}
+
+func TestLimitedContext(t *testing.T) {
+	e := Limited(GoExecutor{}, 1)
+
+	release := make(chan struct{})
+	assert.NoError(t, e.TrySubmit(func() {
+		<-release
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.TrySubmitContext(ctx, func() {}), context.DeadlineExceeded)
+
+	close(release)
+	done := make(chan struct{})
+	assert.NoError(t, e.TrySubmitContext(context.Background(), func() {
+		close(done)
+	}))
+	<-done
+}
+
+func TestLimitedWeighted(t *testing.T) {
+	e := Limited(GoExecutor{}, 2)
+	assert.ErrorIs(t, e.TrySubmitWeighted(context.Background(), 3, func() {}), ErrWeightExceeded)
+	assert.ErrorIs(t, e.TrySubmitWeighted(context.Background(), 0, func() {}), ErrInvalidWeight)
+	assert.ErrorIs(t, e.TrySubmitWeighted(context.Background(), -1, func() {}), ErrInvalidWeight)
+
+	release := make(chan struct{})
+	assert.NoError(t, e.TrySubmitWeighted(context.Background(), 1, func() {
+		<-release
+	}))
+
+	var mu sync.Mutex
+	var order []int
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		_ = e.TrySubmitWeighted(context.Background(), 2, func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, 2)
+			mu.Unlock()
+		})
+	}()
+	assert.Eventually(t, func() bool {
+		e.sem.mu.Lock()
+		defer e.sem.mu.Unlock()
+		return e.sem.waiters.Len() == 1
+	}, time.Second, time.Millisecond)
+
+	// FIFO: the waiter of weight 1 must not bypass the waiter of weight 2, even if there is capacity for it
+	go func() {
+		_ = e.TrySubmitWeighted(context.Background(), 1, func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, 1)
+			mu.Unlock()
+		})
+	}()
+	assert.Eventually(t, func() bool {
+		e.sem.mu.Lock()
+		defer e.sem.mu.Unlock()
+		return e.sem.waiters.Len() == 2
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{2, 1}, order)
+}
+
+func TestLimitedInnerRejected(t *testing.T) {
+	inner := Graceful(GoExecutor{})
+	assert.NoError(t, inner.Shutdown(context.Background()))
+
+	e := Limited(inner, 1)
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+}
+
+func TestRateLimited(t *testing.T) {
+	e := RateLimited(GoExecutor{}, 200, 2)
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		e.Submit(wg.Done)
+	}
+	wg.Wait()
+	// 2 tasks by burst, and 10 tasks by rate
+	assert.GreaterOrEqual(t, time.Since(start), 45*time.Millisecond)
+}
+
+func TestRateLimitedContext(t *testing.T) {
+	e := RateLimited(GoExecutor{}, 10, 1)
+	assert.NoError(t, e.TrySubmit(func() {}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, e.TrySubmitContext(ctx, func() {}), context.DeadlineExceeded)
+
+	// the token reserved by the cancelled submission is given back
+	start := time.Now()
+	assert.NoError(t, e.TrySubmit(func() {}))
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestLimitedInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		Limited(nil, 1)
+	})
+	assert.Panics(t, func() {
+		Limited(GoExecutor{}, 0)
+	})
+	assert.Panics(t, func() {
+		RateLimited(nil, 1, 1)
+	})
+	assert.Panics(t, func() {
+		RateLimited(GoExecutor{}, 0, 1)
+	})
+	assert.Panics(t, func() {
+		RateLimited(GoExecutor{}, 1, 0)
+	})
+}
@@ -0,0 +1,229 @@
+package executors
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// PrioritySubmitter is implemented by executors that order queued tasks by priority.
+//
+// A larger priority runs earlier. TrySubmitPriority has the same rejection semantics as TrySubmitter.TrySubmit.
+type PrioritySubmitter interface {
+	TrySubmitPriority(priority int, f func()) error
+}
+
+// TrySubmitPriority submits f with the given priority to e, using e.TrySubmitPriority if e implements
+// PrioritySubmitter. Otherwise, the priority is ignored and it falls back to TrySubmit.
+func TrySubmitPriority(e Executor, priority int, f func()) error {
+	if ps, ok := e.(PrioritySubmitter); ok {
+		return ps.TrySubmitPriority(priority, f)
+	}
+	return TrySubmit(e, f)
+}
+
+// PriorityExecutor runs at most workers tasks concurrently on the inner executor, and queues the others
+// in a priority queue, where tasks with larger priority are executed first and tasks with the same priority
+// are executed in submission order.
+//
+// To prevent starvation of low priority tasks, if aging is positive, the priority of a queued task is raised by 1
+// for each aging duration it has waited. Since all queued tasks age at the same rate, aging never reorders
+// tasks submitted with the same priority.
+type PriorityExecutor struct {
+	inner   Executor
+	workers int
+	aging   time.Duration
+	epoch   time.Time
+
+	mu       sync.Mutex
+	queue    priorityQueue
+	running  int           // number of workers, including the starting ones
+	starting int           // number of workers being submitted to the inner executor
+	started  chan struct{} // closed once a starting worker is accepted or all of them are rejected
+	seq      uint64
+}
+
+// Priority creates a PriorityExecutor running at most workers tasks concurrently on inner.
+//
+// Passing nil executor or non-positive workers will panic.
+func Priority(inner Executor, workers int, aging time.Duration) *PriorityExecutor {
+	if inner == nil {
+		panic("executor is nil")
+	}
+	if workers <= 0 {
+		panic("workers must be positive")
+	}
+	return &PriorityExecutor{inner: inner, workers: workers, aging: aging, epoch: time.Now()}
+}
+
+// Submit submits f with priority 0.
+func (e *PriorityExecutor) Submit(f func()) {
+	_ = e.TrySubmitPriority(0, f)
+}
+
+// TrySubmit submits f with priority 0.
+func (e *PriorityExecutor) TrySubmit(f func()) error {
+	return e.TrySubmitPriority(0, f)
+}
+
+// SubmitPriority submits f with the given priority.
+func (e *PriorityExecutor) SubmitPriority(priority int, f func()) {
+	_ = e.TrySubmitPriority(priority, f)
+}
+
+// TrySubmitPriority submits f with the given priority. It returns an error only if the inner executor
+// rejects to start a worker while no other worker is running, in which case all queued tasks are rejected.
+// Callers queuing tasks while the only workers are starting wait for the result of starting.
+func (e *PriorityExecutor) TrySubmitPriority(priority int, f func()) error {
+	e.mu.Lock()
+	it := &priorityTask{f: f, seq: e.seq, key: int64(priority)}
+	if e.aging > 0 {
+		// effective priority is priority + waited/aging, comparing it between two tasks at the same moment
+		// equals to comparing priority - enqueued/aging, which is static for each task.
+		it.key = int64(priority)*int64(e.aging) - int64(time.Since(e.epoch))
+	}
+	e.seq++
+	heap.Push(&e.queue, it)
+	start := e.running < e.workers
+	if !start {
+		return e.await(it)
+	}
+	e.running++
+	e.starting++
+	e.mu.Unlock()
+
+	err := TrySubmit(e.inner, e.work)
+	e.mu.Lock()
+	e.starting--
+	if err == nil {
+		e.confirm()
+		e.mu.Unlock()
+		return nil
+	}
+	e.running--
+	if e.running == 0 {
+		e.reject(err)
+		e.mu.Unlock()
+		return err
+	}
+	// If there are other workers, they will execute the task eventually.
+	return e.await(it)
+}
+
+// await releases e.mu, and waits for the result of starting workers if there is no accepted worker.
+// It must be called with e.mu held.
+func (e *PriorityExecutor) await(it *priorityTask) error {
+	if e.running > e.starting {
+		e.mu.Unlock()
+		return nil
+	}
+	if e.started == nil {
+		e.started = make(chan struct{})
+	}
+	started := e.started
+	e.mu.Unlock()
+
+	<-started
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return it.err
+}
+
+// confirm wakes up the callers waiting for starting workers, must be called with e.mu held.
+func (e *PriorityExecutor) confirm() {
+	if e.started != nil {
+		close(e.started)
+		e.started = nil
+	}
+}
+
+// reject drops all queued tasks with err after no worker is left, must be called with e.mu held.
+func (e *PriorityExecutor) reject(err error) {
+	for e.queue.Len() > 0 {
+		heap.Pop(&e.queue).(*priorityTask).err = err
+	}
+	e.confirm()
+}
+
+// Len returns the number of queued tasks, not including running tasks.
+func (e *PriorityExecutor) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.queue.Len()
+}
+
+func (e *PriorityExecutor) work() {
+	for {
+		e.mu.Lock()
+		if e.queue.Len() == 0 {
+			e.running--
+			e.mu.Unlock()
+			return
+		}
+		it := heap.Pop(&e.queue).(*priorityTask)
+		e.mu.Unlock()
+
+		e.exec(it.f)
+	}
+}
+
+// exec runs f, and if f panics, restarts the worker on the inner executor before propagating the panic.
+// If the inner executor rejects the restart and no other worker is left, the queued tasks are dropped.
+func (e *PriorityExecutor) exec(f func()) {
+	normal := false
+	defer func() {
+		if !normal {
+			if err := TrySubmit(e.inner, e.work); err != nil {
+				e.mu.Lock()
+				e.running--
+				if e.running == 0 {
+					e.reject(err)
+				}
+				e.mu.Unlock()
+			}
+		}
+	}()
+	f()
+	normal = true
+}
+
+type priorityTask struct {
+	f     func()
+	err   error // the rejection error if dropped
+	key   int64
+	seq   uint64
+	index int
+}
+
+type priorityQueue []*priorityTask
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].key != q[j].key {
+		return q[i].key > q[j].key
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q priorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *priorityQueue) Push(x any) {
+	it := x.(*priorityTask)
+	it.index = len(*q)
+	*q = append(*q, it)
+}
+
+func (q *priorityQueue) Pop() any {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	it.index = -1
+	*q = old[:n-1]
+	return it
+}
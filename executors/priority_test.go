@@ -0,0 +1,187 @@
+package executors
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityOrder(t *testing.T) {
+	e := Priority(GoExecutor{}, 1, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []int
+	wg := sync.WaitGroup{}
+	for _, prio := range []int{1, 3, 2, 3, 0} {
+		prio := prio
+		wg.Add(1)
+		e.SubmitPriority(prio, func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, prio)
+			mu.Unlock()
+		})
+	}
+	assert.Equal(t, 5, e.Len())
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{3, 3, 2, 1, 0}, order)
+	assert.Equal(t, 0, e.Len())
+}
+
+func TestPriorityAging(t *testing.T) {
+	e := Priority(GoExecutor{}, 1, time.Millisecond)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []int
+	wg := sync.WaitGroup{}
+	submit := func(prio int) {
+		wg.Add(1)
+		e.SubmitPriority(prio, func() {
+			defer wg.Done()
+			mu.Lock()
+			order = append(order, prio)
+			mu.Unlock()
+		})
+	}
+	submit(0)
+	time.Sleep(20 * time.Millisecond)
+	submit(5)
+	submit(100)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, []int{100, 0, 5}, order)
+}
+
+func TestPriorityWorkers(t *testing.T) {
+	e := Priority(GoExecutor{}, 4, 0)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		e.Submit(func() {
+			defer wg.Done()
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, maxRunning, 4)
+}
+
+func TestPriorityPanic(t *testing.T) {
+	e := Priority(ExecutorFunc(func(f func()) {
+		go func() {
+			defer func() {
+				_ = recover()
+			}()
+			f()
+		}()
+	}), 1, 0)
+
+	release := make(chan struct{})
+	e.Submit(func() {
+		<-release
+		panic("panic")
+	})
+	done := make(chan struct{})
+	e.Submit(func() {
+		close(done)
+	})
+	close(release)
+	<-done
+}
+
+func TestPriorityInnerRejected(t *testing.T) {
+	inner := Graceful(GoExecutor{})
+	assert.NoError(t, inner.Shutdown(context.Background()))
+
+	e := Priority(inner, 1, 0)
+	assert.ErrorIs(t, e.TrySubmit(func() {}), ErrExecutorClosed)
+	assert.Equal(t, 0, e.Len())
+}
+
+func TestPriorityInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		Priority(nil, 1, 0)
+	})
+	assert.Panics(t, func() {
+		Priority(GoExecutor{}, 0, 0)
+	})
+}
+
+func TestTrySubmitPriorityFallback(t *testing.T) {
+	done := make(chan struct{})
+	assert.NoError(t, TrySubmitPriority(GoExecutor{}, 1, func() {
+		close(done)
+	}))
+	<-done
+}
+
+func TestPriorityInnerRejectedConcurrently(t *testing.T) {
+	inner := &rejectingExecutor{release: make(chan struct{})}
+	e := Priority(inner, 1, 0)
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- e.TrySubmitPriority(1, func() {})
+	}()
+	assert.Eventually(t, func() bool { return e.Len() == 1 }, time.Second, time.Millisecond)
+	go func() {
+		errs <- e.TrySubmitPriority(2, func() {})
+	}()
+	assert.Eventually(t, func() bool { return e.Len() == 2 }, time.Second, time.Millisecond)
+
+	close(inner.release)
+	assert.ErrorIs(t, <-errs, errRejected)
+	assert.ErrorIs(t, <-errs, errRejected)
+	assert.Equal(t, 0, e.Len())
+}
+
+func TestPriorityPanicRestartRejected(t *testing.T) {
+	e := Priority(&rejectingExecutor{accept: 1}, 1, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	assert.NoError(t, e.TrySubmit(func() {
+		close(started)
+		<-release
+		panic("panic")
+	}))
+	<-started
+	assert.NoError(t, e.TrySubmit(func() {}))
+	assert.Equal(t, 1, e.Len())
+	close(release)
+
+	assert.Eventually(t, func() bool { return e.Len() == 0 }, time.Second, time.Millisecond)
+	assert.ErrorIs(t, e.TrySubmit(func() {}), errRejected)
+}
@@ -0,0 +1,101 @@
+package executors
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrWeightExceeded = errors.New("weight exceeds the limit")
+	ErrInvalidWeight  = errors.New("weight must be positive")
+)
+
+// semaphore is a FIFO weighted semaphore. A waiter is never bypassed by a later waiter,
+// even if the later waiter requires a smaller weight.
+type semaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List // *semaphoreWaiter
+}
+
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+func newSemaphore(size int64) *semaphore {
+	return &semaphore{size: size}
+}
+
+func (s *semaphore) acquire(ctx context.Context, n int64) error {
+	if n <= 0 {
+		return ErrInvalidWeight
+	}
+	if n > s.size {
+		return ErrWeightExceeded
+	}
+
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// Acquired just after ctx is done, give it back.
+			s.cur -= n
+			s.notify()
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// If the removed waiter is the front, the next ones may be satisfied now.
+			if front {
+				s.notify()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (s *semaphore) release(n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.mu.Unlock()
+		panic("semaphore: released more than held")
+	}
+	s.notify()
+	s.mu.Unlock()
+}
+
+// notify wakes up waiters in FIFO order as long as they can be satisfied, must be called with s.mu held.
+func (s *semaphore) notify() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+		w := next.Value.(*semaphoreWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
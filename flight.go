@@ -0,0 +1,102 @@
+package future
+
+import (
+	"sync"
+	"time"
+)
+
+// Flight deduplicates concurrent calls with the same key, like golang.org/x/sync/singleflight,
+// but returns a shared Future instead of blocking the callers.
+//
+// While a call for a key is in flight, Do returns the same Future for the key without calling fn again,
+// and all callers wait on the Future instead of a sync.WaitGroup. Optionally, the result can be shared
+// for a window after the call is done, see SetWindow.
+//
+//	var flight future.Flight[string, *User]
+//	f := flight.Do(id, func() (*User, error) {
+//	    return db.GetUser(id)
+//	})
+//
+// The zero value is ready to use. Calls are submitted to the executor of go-future.
+type Flight[K comparable, V any] struct {
+	mu     sync.Mutex
+	calls  map[K]*flightCall[V]
+	window time.Duration
+	clock  Clock
+}
+
+type flightCall[V any] struct {
+	f     *Future[V]
+	timer Timer
+}
+
+// SetWindow shares the result of a call with the callers of Do for d after the call is done,
+// measured by the Clock of go-future. A non-positive d (the default) shares the result only while in flight.
+func (g *Flight[K, V]) SetWindow(d time.Duration) {
+	g.SetWindowWithClock(clock, d)
+}
+
+// SetWindowWithClock is like SetWindow but measures d with the given Clock.
+func (g *Flight[K, V]) SetWindowWithClock(c Clock, d time.Duration) {
+	g.mu.Lock()
+	g.window = d
+	g.clock = c
+	g.mu.Unlock()
+}
+
+// Do returns the Future of the call in flight (or in the window) for key if any,
+// otherwise calls fn asynchronously like Async and returns its Future, shared by the later callers.
+func (g *Flight[K, V]) Do(key K, fn func() (V, error)) *Future[V] {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		return c.f
+	}
+	if g.calls == nil {
+		g.calls = make(map[K]*flightCall[V])
+	}
+	s := &state[V]{}
+	c := &flightCall[V]{f: &Future[V]{state: s}}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	Async(fn).state.subscribe(func(val V, err error) {
+		// forget the call before completing it, so that the callbacks calling Do start a new call
+		g.done(key, c)
+		s.set(val, err)
+	})
+	return c.f
+}
+
+// Forget forgets key, so that the next call of Do for key calls fn instead of sharing the
+// call in flight or in the window. The callers already waiting for the call are not affected.
+func (g *Flight[K, V]) Forget(key K) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		delete(g.calls, key)
+		if c.timer != nil {
+			c.timer.Stop()
+		}
+	}
+	g.mu.Unlock()
+}
+
+func (g *Flight[K, V]) done(key K, c *flightCall[V]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] != c {
+		// forgotten
+		return
+	}
+	if g.window <= 0 {
+		delete(g.calls, key)
+		return
+	}
+	c.timer = g.clock.AfterFunc(g.window, func() {
+		g.mu.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+	})
+}
@@ -0,0 +1,98 @@
+package future
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlightDo(t *testing.T) {
+	var g Flight[string, int]
+	var calls int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 1, nil
+	}
+
+	f1 := g.Do("a", fn)
+	f2 := g.Do("a", fn)
+	f3 := g.Do("b", fn)
+	assert.Same(t, f1, f2)
+	assert.NotSame(t, f1, f3)
+	close(release)
+
+	val, err := f1.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, val)
+	_, _ = f3.Get()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// not shared after done
+	f4 := g.Do("a", fn)
+	assert.NotSame(t, f1, f4)
+	_, _ = f4.Get()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFlightError(t *testing.T) {
+	var g Flight[int, int]
+	errFoo := errors.New("foo")
+	_, err := g.Do(1, func() (int, error) {
+		return 0, errFoo
+	}).Get()
+	assert.Equal(t, errFoo, err)
+
+	_, err = g.Do(1, func() (int, error) {
+		panic("bar")
+	}).Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestFlightForget(t *testing.T) {
+	var g Flight[string, int]
+	release := make(chan struct{})
+	f1 := g.Do("a", func() (int, error) {
+		<-release
+		return 1, nil
+	})
+	g.Forget("a")
+	f2 := g.Do("a", func() (int, error) {
+		return 2, nil
+	})
+	assert.NotSame(t, f1, f2)
+	val, _ := f2.Get()
+	assert.Equal(t, 2, val)
+
+	close(release)
+	val, _ = f1.Get()
+	assert.Equal(t, 1, val)
+}
+
+func TestFlightWindow(t *testing.T) {
+	var g Flight[string, int]
+	g.SetWindow(50 * time.Millisecond)
+
+	var calls int32
+	fn := func() (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+	f1 := g.Do("a", fn)
+	_, _ = f1.Get()
+	assert.Same(t, f1, g.Do("a", fn))
+
+	var f2 *Future[int]
+	assert.Eventually(t, func() bool {
+		f2 = g.Do("a", fn)
+		return f2 != f1
+	}, time.Second, 10*time.Millisecond)
+	val, _ := f2.Get()
+	assert.Equal(t, 2, val)
+
+	g.Forget("a")
+	assert.NotSame(t, f2, g.Do("a", fn))
+}
@@ -0,0 +1,233 @@
+package futurecache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+// Loader loads the value of key on a cache miss.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Options configures a Cache. Zero fields disable the corresponding feature.
+type Options struct {
+	// TTL is how long a loaded value is cached, zero means forever.
+	TTL time.Duration
+	// MaxSize is the maximum number of cached keys, the least recently used keys are evicted beyond it.
+	// Zero means unlimited.
+	MaxSize int
+	// ErrorTTL is how long a load error is cached (negative caching), zero means errors are not cached
+	// and the next Get loads again.
+	ErrorTTL time.Duration
+	// RefreshAfter enables refresh-ahead: once a value is older than RefreshAfter (but not expired by TTL),
+	// Get reloads it in background and returns the stale value until the reload succeeds.
+	// If the reload fails, the stale value is kept until it expires.
+	RefreshAfter time.Duration
+	// Clock measures TTLs, default is the Clock of go-future.
+	Clock future.Clock
+	// Executor runs the loader, default is the executor of go-future.
+	Executor future.Executor
+}
+
+// Cache is an async loading cache, which caches the Futures of loads rather than values,
+// so that concurrent Gets for a key share a single load.
+//
+//	c := futurecache.New(func(ctx context.Context, id string) (*User, error) {
+//	    return db.GetUser(ctx, id)
+//	}, futurecache.Options{TTL: time.Minute, MaxSize: 10000})
+//	user, err := c.Get(ctx, id).Get()
+//
+// Loads are submitted to the executor of go-future unless Options.Executor is set.
+type Cache[K comparable, V any] struct {
+	loader Loader[K, V]
+	opts   Options
+
+	mu      sync.Mutex
+	entries map[K]*entry[K, V]
+	lru     *list.List // of *entry, most recently used first
+}
+
+type entry[K comparable, V any] struct {
+	key        K
+	f          *future.Future[V]
+	loaded     time.Time // when f was done, zero if loading
+	failed     bool
+	refreshing bool
+	elem       *list.Element
+}
+
+// New creates a Cache loading values with loader.
+func New[K comparable, V any](loader Loader[K, V], opts Options) *Cache[K, V] {
+	if opts.Clock == nil {
+		opts.Clock = future.DefaultClock()
+	}
+	return &Cache[K, V]{
+		loader:  loader,
+		opts:    opts,
+		entries: make(map[K]*entry[K, V]),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the Future of the value of key, which is cached or loaded by the loader.
+//
+// The loader is called with a context carrying the values of ctx but not its cancellation, since the load
+// is shared by all concurrent Gets for key. The returned Future fails with ctx.Err() if ctx is done
+// before the value is available, without cancelling the load.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) *future.Future[V] {
+	if err := ctx.Err(); err != nil {
+		var zero V
+		return future.Done2(zero, err)
+	}
+
+	c.mu.Lock()
+	now := c.opts.Clock.Now()
+	e, ok := c.entries[key]
+	if ok && c.expired(e, now) {
+		c.remove(e)
+		ok = false
+	}
+	var p *future.Promise[V]
+	refresh := false
+	if !ok {
+		e = &entry[K, V]{key: key}
+		e.elem = c.lru.PushFront(e)
+		c.entries[key] = e
+		c.evict()
+		p = future.NewPromise[V]()
+		e.f = p.Future()
+	} else {
+		c.lru.MoveToFront(e.elem)
+		if c.opts.RefreshAfter > 0 && !e.loaded.IsZero() && !e.failed && !e.refreshing &&
+			now.Sub(e.loaded) >= c.opts.RefreshAfter {
+			e.refreshing = true
+			refresh = true
+		}
+	}
+	f := e.f
+	c.mu.Unlock()
+
+	// the load is started without holding c.mu, since it may be done synchronously
+	if p != nil || refresh {
+		c.load(ctx, e, p)
+	}
+	return withContext(ctx, f)
+}
+
+// Invalidate removes key from the cache, the next Get for key loads it again.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.mu.Unlock()
+}
+
+// Len returns the number of cached keys, including the loading ones.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// load loads e, and completes p with the result if not nil, otherwise it refreshes e.
+// It must be called without holding c.mu.
+func (c *Cache[K, V]) load(ctx context.Context, e *entry[K, V], p *future.Promise[V]) {
+	fn := func(ctx context.Context) (V, error) {
+		return c.loader(ctx, e.key)
+	}
+	var f *future.Future[V]
+	if c.opts.Executor != nil {
+		f = future.CtxSubmit(detached{ctx}, c.opts.Executor, fn)
+	} else {
+		f = future.CtxAsync(detached{ctx}, fn)
+	}
+	f.Subscribe(func(val V, err error) {
+		c.update(e, p == nil, val, err)
+		if p != nil {
+			p.Set(val, err)
+		}
+	})
+}
+
+// update updates e with the result of a load.
+func (c *Cache[K, V]) update(e *entry[K, V], refresh bool, val V, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[e.key] != e {
+		// invalidated, expired or evicted while loading
+		return
+	}
+	if refresh {
+		e.refreshing = false
+		if err != nil {
+			// keep the stale value
+			return
+		}
+		e.f = future.Done(val)
+	}
+	if err != nil && c.opts.ErrorTTL <= 0 {
+		c.remove(e)
+		return
+	}
+	e.failed = err != nil
+	e.loaded = c.opts.Clock.Now()
+}
+
+// expired reports whether e is expired at now, must be called with c.mu held.
+func (c *Cache[K, V]) expired(e *entry[K, V], now time.Time) bool {
+	if e.loaded.IsZero() {
+		return false
+	}
+	ttl := c.opts.TTL
+	if e.failed {
+		ttl = c.opts.ErrorTTL
+	}
+	return ttl > 0 && now.Sub(e.loaded) >= ttl
+}
+
+// evict removes the least recently used entries beyond MaxSize, must be called with c.mu held.
+func (c *Cache[K, V]) evict() {
+	for c.opts.MaxSize > 0 && c.lru.Len() > c.opts.MaxSize {
+		c.remove(c.lru.Back().Value.(*entry[K, V]))
+	}
+}
+
+func (c *Cache[K, V]) remove(e *entry[K, V]) {
+	delete(c.entries, e.key)
+	c.lru.Remove(e.elem)
+}
+
+// withContext returns a Future which is completed with the result of f, or fails with ctx.Err()
+// if ctx is done before f.
+func withContext[V any](ctx context.Context, f *future.Future[V]) *future.Future[V] {
+	if ctx.Done() == nil || f.Done() {
+		return f
+	}
+	p := future.NewPromise[V]()
+	f.Subscribe(func(val V, err error) {
+		p.SetSafety(val, err)
+	})
+	ctx, cancel := future.WithFutureCancel(ctx, f)
+	future.FromContext(ctx).Subscribe(func(_ struct{}, err error) {
+		cancel()
+		if err == context.Canceled && f.Done() {
+			return
+		}
+		var zero V
+		p.SetSafety(zero, err)
+	})
+	return p.Future()
+}
+
+// detached is a context carrying the values of the parent but never cancelled.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
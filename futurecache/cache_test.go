@@ -0,0 +1,187 @@
+package futurecache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jizhuozhi/go-future/executors"
+	"github.com/jizhuozhi/go-future/futuretest"
+	"github.com/stretchr/testify/assert"
+)
+
+type counter struct {
+	calls int32
+}
+
+func (c *counter) load(_ context.Context, key string) (string, error) {
+	n := atomic.AddInt32(&c.calls, 1)
+	return key + string(rune('0'+n)), nil
+}
+
+func (c *counter) count() int32 {
+	return atomic.LoadInt32(&c.calls)
+}
+
+func TestCacheDedupe(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	c := New(func(ctx context.Context, key int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return key * 2, nil
+	}, Options{})
+
+	f1 := c.Get(context.Background(), 1)
+	f2 := c.Get(context.Background(), 1)
+	assert.Same(t, f1, f2)
+	close(release)
+
+	val, err := f1.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, val)
+	val, _ = c.Get(context.Background(), 1).Get()
+	assert.Equal(t, 2, val)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestCacheTTL(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var l counter
+	c := New(l.load, Options{TTL: time.Minute, Clock: clock})
+
+	val, _ := c.Get(context.Background(), "a").Get()
+	assert.Equal(t, "a1", val)
+	clock.Advance(59 * time.Second)
+	val, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, "a1", val)
+	clock.Advance(time.Second)
+	val, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, "a2", val)
+}
+
+func TestCacheLRU(t *testing.T) {
+	var l counter
+	c := New(l.load, Options{MaxSize: 2})
+	ctx := context.Background()
+
+	_, _ = c.Get(ctx, "a").Get()
+	_, _ = c.Get(ctx, "b").Get()
+	_, _ = c.Get(ctx, "a").Get()
+	_, _ = c.Get(ctx, "c").Get() // evicts b
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, int32(3), l.count())
+
+	_, _ = c.Get(ctx, "a").Get()
+	assert.Equal(t, int32(3), l.count())
+	val, _ := c.Get(ctx, "b").Get()
+	assert.Equal(t, "b4", val)
+}
+
+func TestCacheNegative(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	errFoo := errors.New("foo")
+	var calls int32
+	loader := func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "", errFoo
+	}
+
+	c := New(loader, Options{Clock: clock})
+	_, err := c.Get(context.Background(), "a").Get()
+	assert.Equal(t, errFoo, err)
+	_, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "errors are not cached")
+
+	c = New(loader, Options{ErrorTTL: time.Second, Clock: clock})
+	_, _ = c.Get(context.Background(), "a").Get()
+	_, err = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, errFoo, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	clock.Advance(time.Second)
+	_, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestCacheRefreshAhead(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var calls int32
+	release := make(chan struct{}, 1)
+	c := New(func(ctx context.Context, key string) (int32, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n > 1 {
+			<-release
+		}
+		return n, nil
+	}, Options{TTL: time.Minute, RefreshAfter: 30 * time.Second, Clock: clock})
+	ctx := context.Background()
+
+	val, _ := c.Get(ctx, "a").Get()
+	assert.Equal(t, int32(1), val)
+	clock.Advance(30 * time.Second)
+
+	// stale value returned while reloading, only one reload
+	val, _ = c.Get(ctx, "a").Get()
+	assert.Equal(t, int32(1), val)
+	val, _ = c.Get(ctx, "a").Get()
+	assert.Equal(t, int32(1), val)
+
+	release <- struct{}{}
+	assert.Eventually(t, func() bool {
+		val, _ := c.Get(ctx, "a").Get()
+		return val == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+type ctxKey struct{}
+
+func TestCacheContext(t *testing.T) {
+	release := make(chan struct{})
+	c := New(func(ctx context.Context, key string) (string, error) {
+		assert.NoError(t, ctx.Err())
+		assert.Equal(t, "v", ctx.Value(ctxKey{}))
+		<-release
+		return key, nil
+	}, Options{})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	f := c.Get(ctx, "a")
+	cancel()
+	_, err := f.Get()
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = c.Get(ctx, "a").Get()
+	assert.Equal(t, context.Canceled, err)
+
+	f = c.Get(context.Background(), "a")
+	close(release)
+	val, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", val)
+}
+
+func TestCacheInlineExecutor(t *testing.T) {
+	clock := futuretest.NewClock(time.Unix(0, 0))
+	var l counter
+	c := New(l.load, Options{
+		TTL:          time.Minute,
+		RefreshAfter: 30 * time.Second,
+		Clock:        clock,
+		Executor: executors.ExecutorFunc(func(f func()) {
+			f()
+		}),
+	})
+
+	val, err := c.Get(context.Background(), "a").Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "a1", val)
+
+	clock.Advance(30 * time.Second)
+	val, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, "a1", val, "stale value returned while refreshing")
+	val, _ = c.Get(context.Background(), "a").Get()
+	assert.Equal(t, "a2", val)
+}
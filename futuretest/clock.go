@@ -0,0 +1,118 @@
+package futuretest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+// Clock is a fake future.Clock whose time only moves when Advance or Set is called.
+//
+// Timers created by AfterFunc are fired synchronously in the goroutine calling Advance or Set,
+// in the order of their deadlines, and timers with the same deadline in creation order.
+// A timer with non-positive duration is due immediately, and fired by the next Advance (e.g. Advance(0)).
+//
+//	clock := futuretest.NewClock(time.Now())
+//	f := future.TimeoutWithClock(clock, p.Future(), time.Second)
+//	clock.Advance(time.Second) // f fails with future.ErrTimeout
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers []*timer
+}
+
+// NewClock creates a Clock starting at now.
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current fake time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc registers f to be called once the fake time reaches Now() + d.
+func (c *Clock) AfterFunc(d time.Duration, f func()) future.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &timer{clock: c, when: c.now.Add(d), seq: c.seq, f: f}
+	c.seq++
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Timers returns the number of pending timers.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// Advance moves the fake time forward by d, and fires all timers due until then.
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the fake time to t, and fires all timers due until then. The time never moves backward,
+// setting a time before Now only fires the timers which are already due.
+func (c *Clock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		next := -1
+		for i, tm := range c.timers {
+			if tm.when.After(t) {
+				continue
+			}
+			if next < 0 || tm.before(c.timers[next]) {
+				next = i
+			}
+		}
+		if next < 0 {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mu.Unlock()
+			return
+		}
+		tm := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		if tm.when.After(c.now) {
+			c.now = tm.when
+		}
+		c.mu.Unlock()
+
+		tm.f()
+	}
+}
+
+type timer struct {
+	clock *Clock
+	when  time.Time
+	seq   uint64
+	f     func()
+}
+
+func (t *timer) before(o *timer) bool {
+	if !t.when.Equal(o.when) {
+		return t.when.Before(o.when)
+	}
+	return t.seq < o.seq
+}
+
+// Stop removes the timer from its clock, and returns false if the timer has already fired or been stopped.
+func (t *timer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, tm := range c.timers {
+		if tm == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package futuretest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	assert.Equal(t, start, c.Now())
+
+	var order []int
+	c.AfterFunc(2*time.Second, func() { order = append(order, 2) })
+	c.AfterFunc(time.Second, func() {
+		order = append(order, 1)
+		// timers registered by callbacks are fired too if they are due
+		c.AfterFunc(500*time.Millisecond, func() { order = append(order, 15) })
+	})
+	stopped := c.AfterFunc(time.Second, func() { order = append(order, -1) })
+	c.AfterFunc(3*time.Second, func() { order = append(order, 3) })
+	assert.Equal(t, 4, c.Timers())
+
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	c.Advance(2 * time.Second)
+	assert.Equal(t, []int{1, 15, 2}, order)
+	assert.Equal(t, start.Add(2*time.Second), c.Now())
+	assert.Equal(t, 1, c.Timers())
+
+	c.Set(start)
+	assert.Equal(t, start.Add(2*time.Second), c.Now())
+
+	c.Set(start.Add(time.Hour))
+	assert.Equal(t, []int{1, 15, 2, 3}, order)
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+	assert.Equal(t, 0, c.Timers())
+}
+
+func TestClockTimeout(t *testing.T) {
+	c := NewClock(time.Now())
+	defer future.SetClock(future.DefaultClock())
+	future.SetClock(c)
+
+	{
+		p := future.NewPromise[int]()
+		f := future.Timeout(p.Future(), time.Second)
+		c.Advance(999 * time.Millisecond)
+		assert.False(t, f.Done())
+		c.Advance(time.Millisecond)
+		assert.True(t, f.Done())
+		_, err := f.Get()
+		assert.ErrorIs(t, err, future.ErrTimeout)
+	}
+	{
+		p := future.NewPromise[int]()
+		f := future.Until(p.Future(), c.Now().Add(time.Second))
+		assert.Equal(t, 1, c.Timers())
+		p.Set(1, nil)
+		assert.Equal(t, 0, c.Timers())
+		val, err := f.Get()
+		assert.Equal(t, 1, val)
+		assert.NoError(t, err)
+	}
+}
+
+func TestClockTimeoutWithClock(t *testing.T) {
+	c := NewClock(time.Now())
+
+	p := future.NewPromise[int]()
+	f1 := future.TimeoutWithClock(c, p.Future(), time.Second)
+	f2 := future.UntilWithClock(c, p.Future(), c.Now().Add(2*time.Second))
+	c.Advance(time.Second)
+	_, err := f1.Get()
+	assert.ErrorIs(t, err, future.ErrTimeout)
+	assert.False(t, f2.Done())
+
+	p.Set(1, nil)
+	val, err := f2.Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, c.Timers())
+}
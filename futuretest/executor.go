@@ -0,0 +1,69 @@
+package futuretest
+
+import (
+	"sync"
+)
+
+// ManualExecutor is an Executor which queues submitted tasks until they are stepped explicitly,
+// so that tests can drive the interleavings of tasks deterministically.
+//
+//	e := futuretest.NewManualExecutor()
+//	f := future.Submit(e, func() (int, error) { return 1, nil })
+//	e.Step() // runs the task in the current goroutine
+//	val, err := f.Get()
+//
+// Tasks are executed in the goroutine calling Step, StepAt or Run. A task may submit other tasks,
+// which are queued after the pending ones.
+type ManualExecutor struct {
+	mu    sync.Mutex
+	tasks []func()
+}
+
+// NewManualExecutor creates a ManualExecutor without pending tasks.
+func NewManualExecutor() *ManualExecutor {
+	return &ManualExecutor{}
+}
+
+// Submit queues f until it is stepped.
+func (e *ManualExecutor) Submit(f func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.tasks = append(e.tasks, f)
+}
+
+// Len returns the number of pending tasks.
+func (e *ManualExecutor) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.tasks)
+}
+
+// Step runs the oldest pending task, and returns false if there is no pending task.
+func (e *ManualExecutor) Step() bool {
+	return e.StepAt(0)
+}
+
+// StepAt runs the i-th oldest pending task, and returns false if there are not enough pending tasks.
+func (e *ManualExecutor) StepAt(i int) bool {
+	e.mu.Lock()
+	if i < 0 || i >= len(e.tasks) {
+		e.mu.Unlock()
+		return false
+	}
+	f := e.tasks[i]
+	e.tasks = append(e.tasks[:i], e.tasks[i+1:]...)
+	e.mu.Unlock()
+
+	f()
+	return true
+}
+
+// Run runs pending tasks in submission order until there is no pending task, including tasks submitted
+// by the running tasks, and returns the number of tasks executed.
+func (e *ManualExecutor) Run() int {
+	n := 0
+	for e.Step() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,62 @@
+package futuretest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future"
+)
+
+func TestManualExecutor(t *testing.T) {
+	e := NewManualExecutor()
+	assert.False(t, e.Step())
+
+	var order []int
+	f1 := future.Submit(e, func() (int, error) {
+		order = append(order, 1)
+		return 1, nil
+	})
+	f2 := future.Submit(e, func() (int, error) {
+		order = append(order, 2)
+		return 2, nil
+	})
+	f3 := future.Submit(e, func() (int, error) {
+		order = append(order, 3)
+		return 3, nil
+	})
+	assert.Equal(t, 3, e.Len())
+	assert.False(t, f1.Done())
+
+	assert.True(t, e.StepAt(1))
+	assert.True(t, f2.Done())
+	assert.False(t, f1.Done())
+	assert.False(t, e.StepAt(2))
+
+	assert.True(t, e.Step())
+	assert.True(t, e.Step())
+	assert.False(t, e.Step())
+	assert.Equal(t, []int{2, 1, 3}, order)
+
+	vals, err := future.AllOf(f1, f2, f3).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, vals)
+}
+
+func TestManualExecutorRun(t *testing.T) {
+	e := NewManualExecutor()
+
+	f := future.ThenAsync(future.Submit(e, func() (int, error) {
+		return 1, nil
+	}), func(val int, err error) *future.Future[int] {
+		return future.Submit(e, func() (int, error) {
+			return val + 1, err
+		})
+	})
+	assert.Equal(t, 2, e.Run())
+	assert.Equal(t, 0, e.Len())
+
+	val, err := f.Get()
+	assert.Equal(t, 2, val)
+	assert.NoError(t, err)
+}
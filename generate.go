@@ -0,0 +1,100 @@
+package future
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Generate returns a Stream of the values yielded by fn, which runs on its own goroutine
+// once the first element is requested.
+//
+// Each call of yield hands a value over to the consumer and blocks until the consumer requests the next
+// element by Next, so fn never runs ahead of the consumer. If ctx is done while waiting, yield returns ctx.Err()
+// and fn should return. The Stream ends when fn returns nil, and terminates with the error of fn otherwise,
+// including the panic of fn as ErrPanic.
+//
+//	s := future.Generate(ctx, func(ctx context.Context, yield func(*Page) error) error {
+//	    for token := ""; ; {
+//	        page, err := client.List(ctx, token)
+//	        if err != nil {
+//	            return err
+//	        }
+//	        if err := yield(page); err != nil {
+//	            return err
+//	        }
+//	        if token = page.NextToken; token == "" {
+//	            return nil
+//	        }
+//	    }
+//	})
+//
+// If the consumer stops requesting before the end, ctx should be cancelled to release the goroutine.
+func Generate[T any](ctx context.Context, fn func(ctx context.Context, yield func(T) error) error) Stream[T] {
+	g := &generator[T]{ctx: ctx, fn: fn, requests: make(chan *state[Item[T]], 1)}
+	return StreamFunc[T](g.next)
+}
+
+type generator[T any] struct {
+	ctx context.Context
+	fn  func(ctx context.Context, yield func(T) error) error
+
+	mu       sync.Mutex
+	started  bool
+	terminal *Future[Item[T]]
+	requests chan *state[Item[T]] // at most one pending request, since Next is called sequentially
+}
+
+func (g *generator[T]) next() *Future[Item[T]] {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.terminal != nil {
+		return g.terminal
+	}
+	w := &state[Item[T]]{}
+	g.requests <- w
+	if !g.started {
+		g.started = true
+		go g.run()
+	}
+	return &Future[Item[T]]{state: w}
+}
+
+func (g *generator[T]) run() {
+	w := <-g.requests
+	var err error
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w, err=%s, stack=%s", ErrPanic, r, debug.Stack())
+		}
+		g.finish(w, err)
+	}()
+
+	err = g.fn(g.ctx, func(val T) error {
+		if err := g.ctx.Err(); err != nil {
+			return err
+		}
+		w.set(Item[T]{Val: val}, nil)
+		select {
+		case w = <-g.requests:
+			return nil
+		case <-g.ctx.Done():
+			return g.ctx.Err()
+		}
+	})
+}
+
+// finish completes the pending request w (if not yet completed) and all later requests with the terminal result.
+func (g *generator[T]) finish(w *state[Item[T]], err error) {
+	g.mu.Lock()
+	g.terminal = Done2(Item[T]{Done: err == nil}, err)
+	select {
+	case r := <-g.requests:
+		// requested after the last value was yielded, but fn did not wait for it
+		r.set(Item[T]{Done: err == nil}, err)
+	default:
+	}
+	g.mu.Unlock()
+	w.set(Item[T]{Done: err == nil}, err)
+}
@@ -0,0 +1,79 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var produced int32
+	s := Generate(context.Background(), func(ctx context.Context, yield func(int) error) error {
+		for i := 0; i < 5; i++ {
+			atomic.AddInt32(&produced, 1)
+			if err := yield(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&produced), "not started until requested")
+
+	it, err := s.Next().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, it.Val)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&produced), "yield blocks until requested")
+
+	vals, err := Collect(s).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, vals)
+	it, err = s.Next().Get()
+	assert.NoError(t, err)
+	assert.True(t, it.Done)
+}
+
+func TestGenerateError(t *testing.T) {
+	errFoo := errors.New("foo")
+	s := Generate(context.Background(), func(ctx context.Context, yield func(int) error) error {
+		_ = yield(1)
+		return errFoo
+	})
+	vals, err := Collect(s).Get()
+	assert.Equal(t, errFoo, err)
+	assert.Nil(t, vals)
+	_, err = s.Next().Get()
+	assert.Equal(t, errFoo, err)
+
+	s = Generate(context.Background(), func(ctx context.Context, yield func(int) error) error {
+		panic("bar")
+	})
+	_, err = s.Next().Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestGenerateCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	s := Generate(ctx, func(ctx context.Context, yield func(int) error) error {
+		for i := 0; ; i++ {
+			if err := yield(i); err != nil {
+				done <- err
+				return err
+			}
+		}
+	})
+	vals, err := Collect(Take(s, 3)).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, vals)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	_, err = s.Next().Get()
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,222 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+var ErrGroupClosed = errors.New("group closed")
+
+// Group is a structured concurrency scope, which owns all tasks spawned through Go and GroupAsync.
+//
+// Tasks are called with the context of the Group, which is cancelled as soon as any task fails,
+// so that the siblings can stop early, and tasks still queued by the limit of concurrency are dropped
+// with the context error. The Future returned by Wait is completed only after all tasks are finished,
+// with the first error if any, and no task can be spawned after that, so that no task outlives the scope.
+//
+//	g := future.NewGroup(ctx)
+//	g.SetLimit(8)
+//	for _, id := range ids {
+//	    id := id
+//	    g.Go(func(ctx context.Context) error {
+//	        return process(ctx, id)
+//	    })
+//	}
+//	_, err := g.Wait().Get()
+//
+// Tasks are submitted to the executor of go-future. Tasks may spawn other tasks in the same Group.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	limit   int
+	active  int         // number of running tasks
+	pending int         // number of unfinished tasks, plus 1 until Wait is called
+	queue   []groupTask // tasks waiting for the limit of concurrency
+	waited  bool
+	err     error
+
+	wait state[struct{}]
+}
+
+type groupTask struct {
+	run  func()
+	fail func(error)
+}
+
+// NewGroup creates a Group whose context is derived from ctx.
+func NewGroup(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel, pending: 1}
+}
+
+// Context returns the context of the Group, which is cancelled when any task fails or all tasks are finished.
+func (g *Group) Context() context.Context {
+	return g.ctx
+}
+
+// SetLimit limits the number of running tasks to n, the others are queued in FIFO order.
+// A non-positive n means no limit. Spawning never blocks the caller.
+func (g *Group) SetLimit(n int) {
+	g.mu.Lock()
+	g.limit = n
+	starts := g.dequeue()
+	g.mu.Unlock()
+
+	for _, t := range starts {
+		g.start(t)
+	}
+}
+
+// Go spawns f in the Group.
+func (g *Group) Go(f func(ctx context.Context) error) {
+	GroupAsync(g, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+}
+
+// GroupAsync spawns f in the Group g and returns a Future of its result.
+//
+// If the Group has already failed, f is not called and the Future fails with the context error of the Group,
+// and if the Future of Wait has already been completed, the Future fails with ErrGroupClosed.
+func GroupAsync[T any](g *Group, f func(ctx context.Context) (T, error)) *Future[T] {
+	s := &state[T]{}
+	fail := func(err error) {
+		var zero T
+		s.set(zero, err)
+	}
+
+	g.mu.Lock()
+	if g.pending == 0 {
+		g.mu.Unlock()
+		fail(ErrGroupClosed)
+		return &Future[T]{state: s}
+	}
+	if g.err != nil {
+		g.mu.Unlock()
+		fail(g.ctx.Err())
+		return &Future[T]{state: s}
+	}
+	g.pending++
+	s.subscribe(func(_ T, err error) {
+		g.done(err)
+	})
+	t := groupTask{run: task(g.ctx, s, f), fail: fail}
+	g.queue = append(g.queue, t)
+	starts := g.dequeue()
+	g.mu.Unlock()
+
+	for _, t := range starts {
+		g.start(t)
+	}
+	return &Future[T]{state: s}
+}
+
+// Wait closes the scope and returns a Future which is completed with the first error of tasks
+// after all tasks are finished. Tasks can still be spawned by running tasks until then.
+func (g *Group) Wait() *Future[struct{}] {
+	g.mu.Lock()
+	last := false
+	if !g.waited {
+		g.waited = true
+		g.pending--
+		last = g.pending == 0
+	}
+	err := g.err
+	g.mu.Unlock()
+
+	if last {
+		g.finish(err)
+	}
+	return &Future[struct{}]{state: &g.wait}
+}
+
+// dequeue pops the tasks which can be started under the limit, must be called with g.mu held.
+func (g *Group) dequeue() []groupTask {
+	n := len(g.queue)
+	if g.limit > 0 && g.limit-g.active < n {
+		n = g.limit - g.active
+	}
+	if n <= 0 {
+		return nil
+	}
+	starts := make([]groupTask, n)
+	copy(starts, g.queue)
+	g.queue = g.queue[n:]
+	g.active += n
+	return starts
+}
+
+// start submits a worker which runs t, and then the queued tasks in a loop as long as the limit allows,
+// so that a finishing task never submits the next one while still holding its slot of the executor,
+// which would deadlock with a bounded executor (e.g. executors.LimitedExecutor).
+func (g *Group) start(t groupTask) {
+	err := executors.TrySubmit(executor, func() {
+		for t.run != nil {
+			t.run()
+			t = g.next()
+		}
+	})
+	if err != nil {
+		g.release()
+		t.fail(err)
+	}
+}
+
+// next is called when a task of a worker is finished, and pops the next queued task for the worker,
+// or releases the worker if there is none or the limit is exceeded.
+func (g *Group) next() groupTask {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.queue) > 0 && (g.limit <= 0 || g.active <= g.limit) {
+		t := g.queue[0]
+		g.queue = g.queue[1:]
+		return t
+	}
+	g.active--
+	return groupTask{}
+}
+
+// release is called when a worker is rejected by the executor, and starts the next queued tasks if any.
+func (g *Group) release() {
+	g.mu.Lock()
+	g.active--
+	starts := g.dequeue()
+	g.mu.Unlock()
+
+	for _, t := range starts {
+		g.start(t)
+	}
+}
+
+// done is called when the Future of a task is completed, either finished or dropped.
+func (g *Group) done(err error) {
+	g.mu.Lock()
+	var dropped []groupTask
+	if err != nil && g.err == nil {
+		g.err = err
+		g.cancel()
+		dropped = g.queue
+		g.queue = nil
+	}
+	g.pending--
+	last := g.pending == 0
+	ferr := g.err
+	g.mu.Unlock()
+
+	for _, t := range dropped {
+		t.fail(g.ctx.Err())
+	}
+	if last {
+		g.finish(ferr)
+	}
+}
+
+func (g *Group) finish(err error) {
+	g.cancel()
+	g.wait.set(struct{}{}, err)
+}
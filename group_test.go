@@ -0,0 +1,170 @@
+package future
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+func TestGroup(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	var counter int32
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error {
+			atomic.AddInt32(&counter, 1)
+			return nil
+		})
+	}
+	f := GroupAsync(g, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	_, err := g.Wait().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&counter))
+	assert.True(t, f.Done())
+	val, err := f.Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+
+	// the scope is closed
+	assert.ErrorIs(t, g.Context().Err(), context.Canceled)
+	_, err = GroupAsync(g, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Get()
+	assert.ErrorIs(t, err, ErrGroupClosed)
+}
+
+func TestGroupEmpty(t *testing.T) {
+	g := NewGroup(context.Background())
+	_, err := g.Wait().Get()
+	assert.NoError(t, err)
+	_, err = g.Wait().Get()
+	assert.NoError(t, err)
+}
+
+func TestGroupCancelOnError(t *testing.T) {
+	g := NewGroup(context.Background())
+
+	sibling := GroupAsync(g, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	g.Go(func(ctx context.Context) error {
+		return errFoo
+	})
+
+	_, err := g.Wait().Get()
+	assert.ErrorIs(t, err, errFoo)
+	assert.True(t, sibling.Done())
+	_, err = sibling.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = GroupAsync(g, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Get()
+	assert.Error(t, err)
+}
+
+func TestGroupLimit(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.SetLimit(2)
+
+	var running, maxRunning int32
+	for i := 0; i < 20; i++ {
+		g.Go(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	_, err := g.Wait().Get()
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestGroupLimitDropQueued(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	g.Go(func(ctx context.Context) error {
+		<-release
+		return errFoo
+	})
+	var called int32
+	queued := GroupAsync(g, func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&called, 1)
+		return 1, nil
+	})
+	close(release)
+
+	_, err := g.Wait().Get()
+	assert.ErrorIs(t, err, errFoo)
+	_, err = queued.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&called))
+}
+
+func TestGroupNested(t *testing.T) {
+	g := NewGroup(context.Background())
+	var counter int32
+	g.Go(func(ctx context.Context) error {
+		time.Sleep(time.Millisecond)
+		g.Go(func(ctx context.Context) error {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&counter, 1)
+			return nil
+		})
+		return nil
+	})
+	_, err := g.Wait().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter))
+}
+
+func TestGroupPanic(t *testing.T) {
+	g := NewGroup(context.Background())
+	g.Go(func(ctx context.Context) error {
+		panic("panic")
+	})
+	_, err := g.Wait().Get()
+	assert.ErrorIs(t, err, ErrPanic)
+}
+
+func TestGroupLimitedExecutor(t *testing.T) {
+	defer SetExecutor(executor)
+	SetExecutor(executors.Limited(executors.GoExecutor{}, 4))
+
+	items := make([]int, 50)
+	done := make(chan error, 1)
+	go func() {
+		fs := make([]*Future[[]int], 8)
+		for i := range fs {
+			fs[i] = ParallelMap(context.Background(), items, 2, func(ctx context.Context, item int) (int, error) {
+				return item, nil
+			})
+		}
+		_, err := AllOf(fs...).Get()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("group deadlocked with a limited executor")
+	}
+}
@@ -0,0 +1,112 @@
+package future
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hedge calls f, and if it does not succeed within delay, calls f again concurrently as a hedged request,
+// up to maxAttempts attempts in total, to cut the tail latency.
+//
+// Each further attempt is launched delay after the previous one, or immediately when the previous one fails.
+// The returned Future follows the semantics of AnyOf: it is completed with the first successful result,
+// or with the first error if all attempts fail. Once it is completed, the contexts of the remaining attempts
+// are cancelled and no more attempts are launched.
+//
+// Each attempt is submitted to the executor of go-future like CtxAsync, and the delay is scheduled by a timer
+// of the Clock of go-future.
+func Hedge[T any](ctx context.Context, delay time.Duration, maxAttempts int, f func(ctx context.Context) (T, error)) *Future[T] {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	h := &hedger[T]{
+		delay:    delay,
+		f:        f,
+		promises: make([]*Promise[T], maxAttempts),
+	}
+	h.ctx, h.cancel = context.WithCancel(ctx)
+
+	fs := make([]*Future[T], maxAttempts)
+	for i := range h.promises {
+		h.promises[i] = NewPromise[T]()
+		fs[i] = h.promises[i].Future()
+	}
+	r := Then(AnyOf(fs...), func(res AnyResult[T], _ error) (T, error) {
+		h.stop()
+		return res.Val, res.Err
+	})
+	h.launch()
+	return r
+}
+
+type hedger[T any] struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	delay    time.Duration
+	f        func(ctx context.Context) (T, error)
+	promises []*Promise[T]
+	launched int32
+
+	mu      sync.Mutex
+	stopped bool
+	timer   Timer // launches the next attempt, re-armed on each launch
+}
+
+func (h *hedger[T]) launch() {
+	i := int(atomic.AddInt32(&h.launched, 1) - 1)
+	if i >= len(h.promises) {
+		return
+	}
+	if err := h.ctx.Err(); err != nil {
+		h.fail(i, err)
+		return
+	}
+
+	h.mu.Lock()
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	if i+1 < len(h.promises) && !h.stopped {
+		h.timer = clock.AfterFunc(h.delay, h.launch)
+	}
+	h.mu.Unlock()
+
+	p := h.promises[i]
+	CtxAsync(h.ctx, h.f).state.subscribe(func(val T, err error) {
+		// the promise may have been failed by fail if ctx is done
+		p.SetSafety(val, err)
+		if err != nil {
+			h.launch()
+		}
+	})
+}
+
+// fail fails the attempts from i on with err since ctx is done, so that AnyOf completes without waiting for them.
+func (h *hedger[T]) fail(i int, err error) {
+	atomic.StoreInt32(&h.launched, int32(len(h.promises)))
+	h.mu.Lock()
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	h.mu.Unlock()
+	var zero T
+	for _, p := range h.promises[i:] {
+		p.SetSafety(zero, err)
+	}
+}
+
+// stop cancels the remaining attempts and the pending timer.
+func (h *hedger[T]) stop() {
+	h.cancel()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopped = true
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+}
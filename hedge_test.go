@@ -0,0 +1,113 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedge(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{})
+	f := Hedge(context.Background(), 10*time.Millisecond, 3, func(ctx context.Context) (int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 1 {
+			// the first attempt is slow, and is cancelled once the hedged one succeeds
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		}
+		return int(n), nil
+	})
+	val, err := f.Get()
+	assert.Equal(t, 2, val)
+	assert.NoError(t, err)
+	<-cancelled
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestHedgeFirstSucceeds(t *testing.T) {
+	var calls int32
+	val, err := Hedge(context.Background(), time.Second, 3, func(ctx context.Context) (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}).Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestHedgeAllFailed(t *testing.T) {
+	var calls int32
+	start := time.Now()
+	_, err := Hedge(context.Background(), time.Hour, 3, func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errFoo
+	}).Get()
+	assert.ErrorIs(t, err, errFoo)
+	// failed attempts launch the next one immediately
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestHedgeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Hedge(ctx, time.Millisecond, 0, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Get()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHedgeContextAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Hedge(ctx, time.Hour, 3, func(ctx context.Context) (int, error) {
+		return 1, nil
+	}).Get()
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	f := Hedge(ctx, time.Hour, 3, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	start := time.Now()
+	_, err = f.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestHedgeDelayAfterPrevious(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	_, err := Hedge(context.Background(), 50*time.Millisecond, 3, func(ctx context.Context) (int, error) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		n := len(starts)
+		mu.Unlock()
+		if n == 1 {
+			time.Sleep(30 * time.Millisecond)
+			return 0, errors.New("foo")
+		}
+		if n == 2 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}).Get()
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, starts, 3)
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[1]), 50*time.Millisecond, "delay after the previous attempt")
+}
@@ -0,0 +1,229 @@
+package future
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+// Unlocker unlocks the AsyncMutex it is acquired from.
+type Unlocker interface {
+	Unlock()
+}
+
+// Releaser releases the permits of the AsyncSemaphore it is acquired from.
+type Releaser interface {
+	Release()
+}
+
+// AsyncMutex is a mutual exclusion lock whose Lock returns a Future instead of blocking,
+// so that callback-style code (e.g. continuations of Then) can serialize access without parking a goroutine.
+//
+//	f := future.ThenAsync(load(), func(v *Value, err error) *future.Future[future.Unlocker] {
+//	    return mu.Lock()
+//	})
+//	future.Then(f, func(u future.Unlocker, err error) (struct{}, error) {
+//	    defer u.Unlock()
+//	    ...
+//	})
+//
+// The lock is FIFO fair, the waiting Futures are completed in the order of Lock, on the goroutine calling Unlock.
+// The zero value is an unlocked AsyncMutex ready to use.
+type AsyncMutex struct {
+	sem AsyncSemaphore
+}
+
+// Lock returns a Future which is completed with an Unlocker once the lock is acquired.
+func (m *AsyncMutex) Lock() *Future[Unlocker] {
+	return m.CtxLock(context.Background())
+}
+
+// CtxLock is like Lock but fails with ctx.Err() if ctx is done before the lock is acquired.
+func (m *AsyncMutex) CtxLock(ctx context.Context) *Future[Unlocker] {
+	return Then(m.sem.acquire(ctx, 1, 1), func(r Releaser, err error) (Unlocker, error) {
+		if err != nil {
+			return nil, err
+		}
+		return unlocker{r}, nil
+	})
+}
+
+// TryLock acquires the lock and returns an Unlocker if it is not locked and no one is waiting for it.
+func (m *AsyncMutex) TryLock() (Unlocker, bool) {
+	r, ok := m.sem.tryAcquire(1, 1)
+	if !ok {
+		return nil, false
+	}
+	return unlocker{r}, true
+}
+
+type unlocker struct {
+	r Releaser
+}
+
+func (u unlocker) Unlock() {
+	u.r.Release()
+}
+
+// AsyncSemaphore is a weighted semaphore whose Acquire returns a Future instead of blocking, see AsyncMutex.
+//
+// The semaphore is FIFO fair: a waiter is never bypassed by a later waiter, even if the later waiter
+// requires fewer permits.
+//
+// An AsyncSemaphore must be created by NewAsyncSemaphore, the zero value has no permits and fails every Acquire.
+type AsyncSemaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List // *asyncSemaphoreWaiter
+}
+
+type asyncSemaphoreWaiter struct {
+	n int64
+	s *state[Releaser]
+}
+
+// NewAsyncSemaphore creates an AsyncSemaphore with the given number of permits.
+func NewAsyncSemaphore(size int64) *AsyncSemaphore {
+	return &AsyncSemaphore{size: size}
+}
+
+// Acquire returns a Future which is completed with a Releaser once n permits are acquired,
+// or fails with executors.ErrWeightExceeded if n is larger than the size of the semaphore,
+// and with executors.ErrInvalidWeight if n is not positive.
+func (s *AsyncSemaphore) Acquire(n int64) *Future[Releaser] {
+	return s.CtxAcquire(context.Background(), n)
+}
+
+// CtxAcquire is like Acquire but fails with ctx.Err() if ctx is done before the permits are acquired.
+//
+// If ctx can be cancelled, a goroutine waits for ctx until the permits are acquired.
+func (s *AsyncSemaphore) CtxAcquire(ctx context.Context, n int64) *Future[Releaser] {
+	return s.acquire(ctx, s.size, n)
+}
+
+// TryAcquire acquires n permits and returns a Releaser if they are available and no one is waiting for them.
+// It returns false if n is not positive.
+func (s *AsyncSemaphore) TryAcquire(n int64) (Releaser, bool) {
+	return s.tryAcquire(s.size, n)
+}
+
+func (s *AsyncSemaphore) tryAcquire(size, n int64) (Releaser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n <= 0 || n > size || size-s.cur < n || s.waiters.Len() > 0 {
+		return nil, false
+	}
+	s.cur += n
+	return &releaser{s: s, size: size, n: n}, true
+}
+
+// acquire acquires n permits of the semaphore with the given size, which is 1 for AsyncMutex.
+func (s *AsyncSemaphore) acquire(ctx context.Context, size, n int64) *Future[Releaser] {
+	if n <= 0 {
+		return Done2[Releaser](nil, executors.ErrInvalidWeight)
+	}
+	if n > size {
+		return Done2[Releaser](nil, executors.ErrWeightExceeded)
+	}
+	if err := ctx.Err(); err != nil {
+		return Done2[Releaser](nil, err)
+	}
+
+	s.mu.Lock()
+	if size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return Done[Releaser](&releaser{s: s, size: size, n: n})
+	}
+	w := &asyncSemaphoreWaiter{n: n, s: &state[Releaser]{}}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	if done := ctx.Done(); done != nil {
+		acquired := make(chan struct{})
+		w.s.subscribe(func(Releaser, error) {
+			close(acquired)
+		})
+		go func() {
+			select {
+			case <-acquired:
+			case <-done:
+				s.mu.Lock()
+				if elem.Value == nil {
+					// acquired just after ctx is done, the Future is already completed
+					s.mu.Unlock()
+					return
+				}
+				front := s.waiters.Front() == elem
+				s.waiters.Remove(elem)
+				elem.Value = nil
+				var ready []*asyncSemaphoreWaiter
+				// If the removed waiter is the front, the next ones may be satisfied now.
+				if front {
+					ready = s.notify(size)
+				}
+				s.mu.Unlock()
+				w.s.set(nil, ctx.Err())
+				s.grant(size, ready)
+			}
+		}()
+	}
+	return &Future[Releaser]{state: w.s}
+}
+
+func (s *AsyncSemaphore) release(size, n int64) {
+	s.mu.Lock()
+	s.cur -= n
+	if s.cur < 0 {
+		s.mu.Unlock()
+		panic("async semaphore: released more than held")
+	}
+	ready := s.notify(size)
+	s.mu.Unlock()
+	s.grant(size, ready)
+}
+
+// notify pops waiters in FIFO order as long as they can be satisfied, must be called with s.mu held.
+// The popped waiters must be granted by grant after s.mu is released, since their callbacks may acquire again.
+func (s *AsyncSemaphore) notify(size int64) []*asyncSemaphoreWaiter {
+	var ready []*asyncSemaphoreWaiter
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return ready
+		}
+		w := next.Value.(*asyncSemaphoreWaiter)
+		if size-s.cur < w.n {
+			return ready
+		}
+		s.cur += w.n
+		s.waiters.Remove(next)
+		next.Value = nil
+		ready = append(ready, w)
+	}
+}
+
+func (s *AsyncSemaphore) grant(size int64, ready []*asyncSemaphoreWaiter) {
+	for _, w := range ready {
+		w.s.set(&releaser{s: s, size: size, n: w.n}, nil)
+	}
+}
+
+type releaser struct {
+	s        *AsyncSemaphore
+	size     int64
+	n        int64
+	released uint32
+}
+
+func (r *releaser) Release() {
+	if !atomic.CompareAndSwapUint32(&r.released, 0, 1) {
+		panic("async semaphore: released twice")
+	}
+	r.s.release(r.size, r.n)
+}
@@ -0,0 +1,125 @@
+package future
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+func TestAsyncMutex(t *testing.T) {
+	var mu AsyncMutex
+	u, err := mu.Lock().Get()
+	assert.NoError(t, err)
+
+	var order []int
+	fs := make([]*Future[struct{}], 5)
+	for i := range fs {
+		i := i
+		fs[i] = Then(mu.Lock(), func(u Unlocker, err error) (struct{}, error) {
+			defer u.Unlock()
+			order = append(order, i)
+			return struct{}{}, err
+		})
+	}
+	_, ok := mu.TryLock()
+	assert.False(t, ok)
+	u.Unlock()
+
+	_, err = AllOf(fs...).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order, "FIFO")
+
+	u, ok = mu.TryLock()
+	assert.True(t, ok)
+	u.Unlock()
+	assert.Panics(t, func() {
+		u.Unlock()
+	})
+}
+
+func TestAsyncMutexConcurrent(t *testing.T) {
+	var mu AsyncMutex
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, _ := mu.Lock().Get()
+			counter++
+			u.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, counter)
+}
+
+func TestAsyncSemaphore(t *testing.T) {
+	s := NewAsyncSemaphore(3)
+	r1, err := s.Acquire(2).Get()
+	assert.NoError(t, err)
+
+	f2 := s.Acquire(2)
+	f3 := s.Acquire(1)
+	assert.False(t, f2.Done())
+	assert.False(t, f3.Done(), "not bypassing the earlier waiter")
+	_, ok := s.TryAcquire(1)
+	assert.False(t, ok)
+
+	r1.Release()
+	r2, err := f2.Get()
+	assert.NoError(t, err)
+	r3, err := f3.Get()
+	assert.NoError(t, err)
+	r2.Release()
+	r3.Release()
+
+	_, err = s.Acquire(4).Get()
+	assert.ErrorIs(t, err, executors.ErrWeightExceeded)
+	_, err = s.Acquire(0).Get()
+	assert.ErrorIs(t, err, executors.ErrInvalidWeight)
+	_, err = s.Acquire(-1).Get()
+	assert.ErrorIs(t, err, executors.ErrInvalidWeight)
+	_, ok = s.TryAcquire(0)
+	assert.False(t, ok)
+	_, ok = s.TryAcquire(-1)
+	assert.False(t, ok)
+}
+
+func TestAsyncSemaphoreCancel(t *testing.T) {
+	s := NewAsyncSemaphore(2)
+	r1, _ := s.Acquire(1).Get()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f2 := s.CtxAcquire(ctx, 2)
+	f3 := s.Acquire(1)
+	assert.False(t, f3.Done())
+	cancel()
+	_, err := f2.Get()
+	assert.Equal(t, context.Canceled, err)
+
+	// the later waiter is satisfied after the front one is cancelled
+	r3, err := f3.Get()
+	assert.NoError(t, err)
+	r3.Release()
+	r1.Release()
+
+	_, err = s.CtxAcquire(ctx, 1).Get()
+	assert.Equal(t, context.Canceled, err)
+
+	var mu AsyncMutex
+	u, _ := mu.Lock().Get()
+	tctx, tcancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer tcancel()
+	_, err = mu.CtxLock(tctx).Get()
+	assert.Equal(t, context.DeadlineExceeded, err)
+	u.Unlock()
+	u, ok := mu.TryLock()
+	assert.True(t, ok)
+	u.Unlock()
+}
@@ -0,0 +1,83 @@
+package future
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ParallelMap calls fn for each of items with at most limit calls running concurrently,
+// and returns a Future of the results in the order of items.
+//
+// Unlike AllOf over Async in a loop, the calls beyond limit are queued rather than submitted,
+// and are submitted to the executor of go-future as running ones finish. A non-positive limit means no limit.
+// On the first error, the context passed to fn is cancelled, the queued calls are dropped,
+// and the Future fails with the error once the running calls are finished.
+//
+//	users := future.ParallelMap(ctx, ids, 8, func(ctx context.Context, id string) (*User, error) {
+//	    return client.GetUser(ctx, id)
+//	})
+func ParallelMap[T any, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) *Future[[]R] {
+	results := make([]R, len(items))
+	g := parallel(ctx, items, limit, func(ctx context.Context, i int, item T) (struct{}, error) {
+		r, err := fn(ctx, item)
+		results[i] = r
+		return struct{}{}, err
+	}, nil)
+	return Then(g.Wait(), func(_ struct{}, err error) ([]R, error) {
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	})
+}
+
+// ParallelForEach is like ParallelMap but fn has no result.
+func ParallelForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) *Future[struct{}] {
+	g := parallel(ctx, items, limit, func(ctx context.Context, _ int, item T) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	}, nil)
+	return g.Wait()
+}
+
+// ParallelMapUnordered is like ParallelMap but streams the result of each item through the returned channel
+// as soon as it is done, with the index of the item, so that the results can be consumed in completion order.
+//
+// The channel yields exactly one result per item and is closed after the last one. After the first error,
+// the dropped items yield the context error. The channel is buffered with len(items), so the calls never
+// block on a slow consumer.
+func ParallelMapUnordered[T any, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) <-chan AnyResult[R] {
+	ch := make(chan AnyResult[R], len(items))
+	if len(items) == 0 {
+		close(ch)
+		return ch
+	}
+	remaining := int32(len(items))
+	parallel(ctx, items, limit, func(ctx context.Context, _ int, item T) (R, error) {
+		return fn(ctx, item)
+	}, func(i int, val R, err error) {
+		ch <- AnyResult[R]{Index: i, Val: val, Err: err}
+		if atomic.AddInt32(&remaining, -1) == 0 {
+			close(ch)
+		}
+	}).Wait()
+	return ch
+}
+
+// parallel spawns fn for each of items in a new Group with the given limit, and calls cb (if not nil)
+// with the result of each item, including the dropped ones.
+func parallel[T any, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, i int, item T) (R, error), cb func(i int, val R, err error)) *Group {
+	g := NewGroup(ctx)
+	g.SetLimit(limit)
+	for i, item := range items {
+		i, item := i, item
+		f := GroupAsync(g, func(ctx context.Context) (R, error) {
+			return fn(ctx, i, item)
+		})
+		if cb != nil {
+			f.state.subscribe(func(val R, err error) {
+				cb(i, val, err)
+			})
+		}
+	}
+	return g
+}
@@ -0,0 +1,106 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelMap(t *testing.T) {
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+	var active, peak int32
+	f := ParallelMap(context.Background(), items, 4, func(ctx context.Context, item int) (int, error) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return item * 2, nil
+	})
+	results, err := f.Get()
+	assert.NoError(t, err)
+	for i, r := range results {
+		assert.Equal(t, i*2, r)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(4))
+
+	results, err = ParallelMap(context.Background(), nil, 4, func(ctx context.Context, item int) (int, error) {
+		return item, nil
+	}).Get()
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestParallelMapFailFast(t *testing.T) {
+	errFoo := errors.New("foo")
+	var calls int32
+	f := ParallelMap(context.Background(), []int{0, 1, 2, 3, 4, 5}, 2, func(ctx context.Context, item int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if item == 0 {
+			return 0, errFoo
+		}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	_, err := f.Get()
+	assert.Equal(t, errFoo, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "queued items are dropped")
+}
+
+func TestParallelForEach(t *testing.T) {
+	var sum int32
+	_, err := ParallelForEach(context.Background(), []int32{1, 2, 3}, 0, func(ctx context.Context, item int32) error {
+		atomic.AddInt32(&sum, item)
+		return nil
+	}).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), sum)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ParallelForEach(ctx, []int32{1}, 1, func(ctx context.Context, item int32) error {
+		return ctx.Err()
+	}).Get()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	ch := ParallelMapUnordered(context.Background(), []int{3, 1, 2}, 0, func(ctx context.Context, item int) (int, error) {
+		time.Sleep(time.Duration(item) * 10 * time.Millisecond)
+		return item * 2, nil
+	})
+	var indexes []int
+	for r := range ch {
+		assert.NoError(t, r.Err)
+		assert.Equal(t, []int{3, 1, 2}[r.Index]*2, r.Val)
+		indexes = append(indexes, r.Index)
+	}
+	assert.Equal(t, []int{1, 2, 0}, indexes)
+
+	errFoo := errors.New("foo")
+	ch = ParallelMapUnordered(context.Background(), []int{0, 1, 2}, 1, func(ctx context.Context, item int) (int, error) {
+		return 0, errFoo
+	})
+	var errs []error
+	for r := range ch {
+		errs = append(errs, r.Err)
+	}
+	assert.Len(t, errs, 3)
+	assert.ElementsMatch(t, []error{errFoo, context.Canceled, context.Canceled}, errs)
+
+	_, ok := <-ParallelMapUnordered(context.Background(), nil, 1, func(ctx context.Context, item int) (int, error) {
+		return item, nil
+	})
+	assert.False(t, ok)
+}
@@ -0,0 +1,149 @@
+package future
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// ProgressPromise is a Promise which can also report progress of type P before the value is set,
+// e.g. partial outputs of a long-running job.
+//
+//	p := future.NewProgressPromise[*Report, float64]()
+//	go func() {
+//	    for i := 1; i <= 100; i++ {
+//	        step(i)
+//	        p.Report(float64(i) / 100)
+//	    }
+//	    p.Set(report, nil)
+//	}()
+//	f := p.Future()
+//	f.OnProgress(func(ratio float64) {
+//	    log.Printf("%.0f%% done", ratio*100)
+//	})
+//	report, err := f.Get()
+//
+// Reporting never blocks and never takes a lock. Each consumer receives progress one at a time on a goroutine
+// started on demand rather than the executor of go-future, since submitting to the executor may block.
+// If a consumer is slower than the reporter, the intermediate progress is dropped so that it only receives
+// the latest one. Progress reported after the value is set is ignored.
+type ProgressPromise[T any, P any] struct {
+	Promise[T]
+	progress progress[P]
+}
+
+// ProgressFuture is the Future of a ProgressPromise, which also exposes the progress.
+type ProgressFuture[T any, P any] struct {
+	*Future[T]
+	progress *progress[P]
+}
+
+// NewProgressPromise creates a new ProgressPromise object.
+func NewProgressPromise[T any, P any]() *ProgressPromise[T, P] {
+	return &ProgressPromise[T, P]{}
+}
+
+// Report reports the latest progress to the consumers.
+func (p *ProgressPromise[T, P]) Report(progress P) {
+	if !p.Free() {
+		return
+	}
+	p.progress.report(progress)
+}
+
+// Future returns a ProgressFuture object associated with the ProgressPromise.
+func (p *ProgressPromise[T, P]) Future() *ProgressFuture[T, P] {
+	return &ProgressFuture[T, P]{Future: p.Promise.Future(), progress: &p.progress}
+}
+
+// OnProgress registers a callback to be called with the progress, starting from the latest reported one if any.
+//
+// NOTE: The callback is called on a goroutine started on demand, and is never called concurrently with itself.
+// Intermediate progress is dropped if the callback is slower than the reporter.
+func (f *ProgressFuture[T, P]) OnProgress(cb func(progress P)) {
+	f.progress.listen(cb)
+}
+
+// Progress returns the latest reported progress, and false if no progress has been reported.
+func (f *ProgressFuture[T, P]) Progress() (P, bool) {
+	u := f.progress.latest()
+	if u == nil {
+		var zero P
+		return zero, false
+	}
+	return u.val, true
+}
+
+type progress[P any] struct {
+	update    unsafe.Pointer // *progressUpdate[P], the latest one
+	listeners unsafe.Pointer // *progressListener[P], a stack
+}
+
+type progressUpdate[P any] struct {
+	seq uint64
+	val P
+}
+
+type progressListener[P any] struct {
+	cb      func(P)
+	running uint32 // 1 if a drain is scheduled or running
+	seen    uint64 // seq of the last delivered update, only accessed by the drain
+	next    *progressListener[P]
+}
+
+func (p *progress[P]) latest() *progressUpdate[P] {
+	return (*progressUpdate[P])(atomic.LoadPointer(&p.update))
+}
+
+func (p *progress[P]) report(val P) {
+	for {
+		old := p.latest()
+		u := &progressUpdate[P]{seq: 1, val: val}
+		if old != nil {
+			u.seq = old.seq + 1
+		}
+		if atomic.CompareAndSwapPointer(&p.update, unsafe.Pointer(old), unsafe.Pointer(u)) {
+			break
+		}
+	}
+	for l := (*progressListener[P])(atomic.LoadPointer(&p.listeners)); l != nil; l = l.next {
+		p.notify(l)
+	}
+}
+
+func (p *progress[P]) listen(cb func(P)) {
+	l := &progressListener[P]{cb: cb}
+	for {
+		head := atomic.LoadPointer(&p.listeners)
+		l.next = (*progressListener[P])(head)
+		if atomic.CompareAndSwapPointer(&p.listeners, head, unsafe.Pointer(l)) {
+			break
+		}
+	}
+	if p.latest() != nil {
+		p.notify(l)
+	}
+}
+
+// notify schedules a drain of l unless one is already scheduled, which will deliver the latest update.
+func (p *progress[P]) notify(l *progressListener[P]) {
+	if !atomic.CompareAndSwapUint32(&l.running, 0, 1) {
+		return
+	}
+	drain := func() {
+		for {
+			if u := p.latest(); u != nil && u.seq > l.seen {
+				l.seen = u.seq
+				l.cb(u.val)
+				continue
+			}
+			seen := l.seen
+			atomic.StoreUint32(&l.running, 0)
+			// double-check the update reported after the check above but before releasing running
+			if u := p.latest(); u == nil || u.seq <= seen || !atomic.CompareAndSwapUint32(&l.running, 0, 1) {
+				return
+			}
+		}
+	}
+	// not submitted to the executor, which may block the reporter (e.g. executors.LimitedExecutor)
+	go drain()
+}
@@ -0,0 +1,80 @@
+package future
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressPromise(t *testing.T) {
+	p := NewProgressPromise[string, int]()
+	f := p.Future()
+
+	_, ok := f.Progress()
+	assert.False(t, ok)
+
+	p.Report(1)
+	val, ok := f.Progress()
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+
+	got := make(chan int, 10)
+	f.OnProgress(func(progress int) {
+		got <- progress
+	})
+	assert.Equal(t, 1, <-got, "starts from the latest progress")
+	p.Report(2)
+	assert.Equal(t, 2, <-got)
+
+	p.Set("done", nil)
+	p.Report(3)
+	val, _ = f.Progress()
+	assert.Equal(t, 2, val, "ignored after set")
+
+	res, err := f.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "done", res)
+	assert.True(t, f.Done())
+}
+
+func TestProgressPromiseSlowConsumer(t *testing.T) {
+	p := NewProgressPromise[struct{}, int]()
+	f := p.Future()
+
+	var mu sync.Mutex
+	var got []int
+	var running int32
+	f.OnProgress(func(progress int) {
+		assert.Equal(t, int32(1), atomic.AddInt32(&running, 1), "never called concurrently")
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		got = append(got, progress)
+		mu.Unlock()
+		atomic.AddInt32(&running, -1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.Report(j)
+			}
+		}()
+	}
+	wg.Wait()
+	p.Report(1000)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) > 0 && got[len(got)-1] == 1000
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Less(t, len(got), 401, "intermediate progress dropped")
+	mu.Unlock()
+}
@@ -0,0 +1,185 @@
+package future
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"time"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+// Backoff computes the delay before the next attempt of Retry, from the number of attempts made so far
+// (starting from 1) and the previous delay (0 before the first retry).
+type Backoff func(attempt int, prev time.Duration) time.Duration
+
+// ConstantBackoff waits d between attempts.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration {
+		return d
+	}
+}
+
+// ExponentialBackoff waits initial before the first retry, and multiplies the delay by multiplier
+// for each following retry, up to max. A multiplier not greater than 1 is treated as 2.
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) Backoff {
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	return func(_ int, prev time.Duration) time.Duration {
+		next := initial
+		if prev > 0 {
+			next = time.Duration(float64(prev) * multiplier)
+		}
+		if next > max || next <= 0 {
+			next = max
+		}
+		return next
+	}
+}
+
+// DecorrelatedJitterBackoff waits a random delay between base and 3 times the previous delay, up to max,
+// which spreads retries of concurrent callers better than exponential backoff.
+//
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+func DecorrelatedJitterBackoff(base, max time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+		next := base
+		if span := int64(prev)*3 - int64(base); span > 0 {
+			next += time.Duration(rand.Int63n(span))
+		}
+		if next > max {
+			next = max
+		}
+		return next
+	}
+}
+
+// RetryPolicy defines when and how Retry retries a failed attempt. The zero value retries all errors
+// immediately and without limit, which should be bounded by the context.
+type RetryPolicy struct {
+	// Backoff computes the delay before the next attempt, nil means no delay.
+	Backoff Backoff
+	// MaxAttempts limits the number of attempts including the first one, 0 means no limit.
+	MaxAttempts int
+	// MaxElapsed gives up when the next attempt would start after MaxElapsed since the first attempt, 0 means no limit.
+	MaxElapsed time.Duration
+	// Retryable classifies whether an error is retryable, nil means all errors are retryable.
+	Retryable func(err error) bool
+	// OnAttempt is called after each attempt with the number of attempts made so far and the error of the attempt.
+	OnAttempt func(attempt int, err error)
+	// Clock measures the delay and the elapsed time, nil means the Clock of go-future.
+	Clock Clock
+}
+
+// Retry calls f until it succeeds or the policy gives up, and returns a Future of the result of the last attempt.
+//
+// Each attempt is submitted to the executor of go-future like CtxAsync, and attempts without delay are made
+// on the same worker. The delay between attempts is scheduled by a timer of the Clock, so no worker of the
+// executor is held during a delay. However, if ctx can be cancelled, a goroutine waits for ctx during each
+// delay, so that the delay is cancelled as soon as ctx is done.
+// If ctx is done, no more attempts are made and the Future fails with ctx.Err(), immediately even during
+// a delay, and also if the deadline of ctx is before the end of the delay.
+func Retry[T any](ctx context.Context, policy RetryPolicy, f func(ctx context.Context) (T, error)) *Future[T] {
+	c := policy.Clock
+	if c == nil {
+		c = clock
+	}
+	r := &retrier[T]{ctx: ctx, policy: policy, clock: c, f: f, start: c.Now()}
+	r.attempt(1, 0)
+	return &Future[T]{state: &r.s}
+}
+
+type retrier[T any] struct {
+	ctx    context.Context
+	policy RetryPolicy
+	clock  Clock
+	f      func(ctx context.Context) (T, error)
+	start  time.Time
+
+	s state[T]
+}
+
+// attempt submits a worker which makes the attempts from n on, see run.
+func (r *retrier[T]) attempt(n int, prev time.Duration) {
+	if err := r.ctx.Err(); err != nil {
+		var zero T
+		r.s.set(zero, err)
+		return
+	}
+	reject(&r.s, executors.TrySubmitContext(r.ctx, executor, func() {
+		r.run(n, prev)
+	}))
+}
+
+// run makes the attempts from n on in a loop as long as there is no delay between them, so that a failed attempt
+// never submits the next one while still holding its slot of the executor, which would deadlock with a bounded
+// executor (e.g. executors.LimitedExecutor). After a delay, the next attempts are made on a new worker.
+func (r *retrier[T]) run(n int, prev time.Duration) {
+	for ; ; n++ {
+		val, err := r.call()
+		if r.policy.OnAttempt != nil {
+			r.policy.OnAttempt(n, err)
+		}
+		if err == nil {
+			r.s.set(val, nil)
+			return
+		}
+		if cerr := r.ctx.Err(); cerr != nil {
+			var zero T
+			r.s.set(zero, cerr)
+			return
+		}
+		if !r.retryable(n, err) {
+			r.s.set(val, err)
+			return
+		}
+		var delay time.Duration
+		if r.policy.Backoff != nil {
+			delay = r.policy.Backoff(n, prev)
+		}
+		next := r.clock.Now().Add(delay)
+		if r.policy.MaxElapsed > 0 && next.Sub(r.start) > r.policy.MaxElapsed {
+			r.s.set(val, err)
+			return
+		}
+		if deadline, ok := r.ctx.Deadline(); ok && deadline.Before(next) {
+			var zero T
+			r.s.set(zero, context.DeadlineExceeded)
+			return
+		}
+		if delay > 0 {
+			schedule(r.ctx, r.clock, delay, func(err error) {
+				if err != nil {
+					var zero T
+					r.s.set(zero, err)
+					return
+				}
+				r.attempt(n+1, delay)
+			})
+			return
+		}
+		prev = delay
+	}
+}
+
+// call calls f and recovers its panic like the tasks of CtxAsync.
+func (r *retrier[T]) call() (val T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w, err=%s, stack=%s", ErrPanic, p, debug.Stack())
+		}
+	}()
+	return r.f(r.ctx)
+}
+
+func (r *retrier[T]) retryable(n int, err error) bool {
+	if r.policy.MaxAttempts > 0 && n >= r.policy.MaxAttempts {
+		return false
+	}
+	return r.policy.Retryable == nil || r.policy.Retryable(err)
+}
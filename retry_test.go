@@ -0,0 +1,177 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+func TestRetry(t *testing.T) {
+	var attempts []int
+	var calls int32
+	f := Retry(context.Background(), RetryPolicy{
+		Backoff:     ConstantBackoff(time.Millisecond),
+		MaxAttempts: 5,
+		OnAttempt: func(attempt int, err error) {
+			attempts = append(attempts, attempt)
+		},
+	}, func(ctx context.Context) (int, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return 0, errFoo
+		}
+		return 1, nil
+	})
+	val, err := f.Get()
+	assert.Equal(t, 1, val)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, attempts)
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	var calls int32
+	_, err := Retry(context.Background(), RetryPolicy{MaxAttempts: 3}, func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errFoo
+	}).Get()
+	assert.ErrorIs(t, err, errFoo)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryMaxElapsed(t *testing.T) {
+	var calls int32
+	_, err := Retry(context.Background(), RetryPolicy{
+		Backoff:    ConstantBackoff(10 * time.Millisecond),
+		MaxElapsed: 25 * time.Millisecond,
+	}, func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, errFoo
+	}).Get()
+	assert.ErrorIs(t, err, errFoo)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	errFatal := errors.New("fatal")
+	var calls int32
+	_, err := Retry(context.Background(), RetryPolicy{
+		Retryable: func(err error) bool {
+			return !errors.Is(err, errFatal)
+		},
+	}, func(ctx context.Context) (int, error) {
+		if atomic.AddInt32(&calls, 1) < 2 {
+			return 0, errFoo
+		}
+		return 0, errFatal
+	}).Get()
+	assert.ErrorIs(t, err, errFatal)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetryContext(t *testing.T) {
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Retry(ctx, RetryPolicy{}, func(ctx context.Context) (int, error) {
+			return 1, nil
+		}).Get()
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := Retry(ctx, RetryPolicy{Backoff: ConstantBackoff(time.Hour)}, func(ctx context.Context) (int, error) {
+			return 0, errFoo
+		}).Get()
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int32
+		_, err := Retry(ctx, RetryPolicy{Backoff: ConstantBackoff(time.Millisecond)}, func(ctx context.Context) (int, error) {
+			if atomic.AddInt32(&calls, 1) == 3 {
+				cancel()
+			}
+			return 0, errFoo
+		}).Get()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		f := Retry(ctx, RetryPolicy{Backoff: ConstantBackoff(time.Hour)}, func(ctx context.Context) (int, error) {
+			return 0, errFoo
+		})
+		time.Sleep(10 * time.Millisecond)
+		assert.False(t, f.Done())
+		cancel()
+		start := time.Now()
+		_, err := f.Get()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second, "cancelled during the delay")
+	}
+}
+
+func TestRetryLimitedExecutor(t *testing.T) {
+	defer SetExecutor(executor)
+	SetExecutor(executors.Limited(executors.GoExecutor{}, 1))
+
+	for _, backoff := range []Backoff{nil, ConstantBackoff(time.Millisecond)} {
+		var calls int32
+		done := make(chan error, 1)
+		go func() {
+			_, err := Retry(context.Background(), RetryPolicy{Backoff: backoff, MaxAttempts: 3}, func(ctx context.Context) (int, error) {
+				atomic.AddInt32(&calls, 1)
+				return 0, errFoo
+			}).Get()
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, errFoo)
+			assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		case <-time.After(5 * time.Second):
+			t.Fatal("retry deadlocked with a limited executor")
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	{
+		b := ConstantBackoff(time.Second)
+		assert.Equal(t, time.Second, b(1, 0))
+		assert.Equal(t, time.Second, b(2, time.Second))
+	}
+	{
+		b := ExponentialBackoff(time.Millisecond, 5*time.Millisecond, 0)
+		var delays []time.Duration
+		var prev time.Duration
+		for i := 1; i <= 5; i++ {
+			prev = b(i, prev)
+			delays = append(delays, prev)
+		}
+		assert.Equal(t, []time.Duration{
+			time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond,
+		}, delays)
+	}
+	{
+		b := DecorrelatedJitterBackoff(time.Millisecond, 100*time.Millisecond)
+		var prev time.Duration
+		for i := 1; i <= 100; i++ {
+			next := b(i, prev)
+			assert.GreaterOrEqual(t, next, time.Millisecond)
+			assert.LessOrEqual(t, next, 100*time.Millisecond)
+			if prev > 0 && next < 100*time.Millisecond {
+				assert.Less(t, next, 3*prev)
+			}
+			prev = next
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package future
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jizhuozhi/go-future/executors"
+)
+
+// After returns a Future which is completed with the current time after d, measured by the Clock of go-future.
+func After(d time.Duration) *Future[time.Time] {
+	return CtxAfter(context.Background(), d)
+}
+
+// CtxAfter is like After but fails with ctx.Err() if ctx is done before d elapses, and the timer is stopped.
+func CtxAfter(ctx context.Context, d time.Duration) *Future[time.Time] {
+	s := &state[time.Time]{}
+	schedule(ctx, clock, d, func(err error) {
+		if err != nil {
+			s.set(time.Time{}, err)
+			return
+		}
+		s.set(clock.Now(), nil)
+	})
+	return &Future[time.Time]{state: s}
+}
+
+// Delay calls f on the executor of go-future after d, and returns a Future of its result.
+func Delay[T any](d time.Duration, f func() (T, error)) *Future[T] {
+	return CtxDelay(context.Background(), d, func(context.Context) (T, error) {
+		return f()
+	})
+}
+
+// CtxDelay is like Delay but passes ctx to f. If ctx is done before d elapses, f is not called
+// and the Future fails with ctx.Err().
+func CtxDelay[T any](ctx context.Context, d time.Duration, f func(ctx context.Context) (T, error)) *Future[T] {
+	s := &state[T]{}
+	schedule(ctx, clock, d, func(err error) {
+		if err == nil {
+			err = executors.TrySubmitContext(ctx, executor, task(ctx, s, f))
+		}
+		reject(s, err)
+	})
+	return &Future[T]{state: s}
+}
+
+// ScheduleAt is like Delay but calls f at t.
+func ScheduleAt[T any](t time.Time, f func() (T, error)) *Future[T] {
+	return Delay(t.Sub(clock.Now()), f)
+}
+
+// CtxScheduleAt is like CtxDelay but calls f at t.
+func CtxScheduleAt[T any](ctx context.Context, t time.Time, f func(ctx context.Context) (T, error)) *Future[T] {
+	return CtxDelay(ctx, t.Sub(clock.Now()), f)
+}
+
+// Every returns a Stream of the results of f called periodically with the given interval, starting one interval
+// after Every is called. The calls are driven by the consumer: f is only called for the requested elements,
+// and ticks missed by a slow consumer are dropped like time.Ticker, so f is called at most once per interval.
+//
+// The Stream terminates with the error of f, or with ctx.Err() once ctx is done. Every panics if interval is not positive.
+//
+//	s := future.Take(future.Every(ctx, time.Second, func(ctx context.Context) (Status, error) {
+//	    return client.Poll(ctx, jobID)
+//	}), 10)
+func Every[T any](ctx context.Context, interval time.Duration, f func(ctx context.Context) (T, error)) Stream[T] {
+	if interval <= 0 {
+		panic("non-positive interval for Every")
+	}
+	tick := clock.Now()
+	var terminal *Future[Item[T]]
+	return StreamFunc[T](func() *Future[Item[T]] {
+		if terminal != nil {
+			return terminal
+		}
+		now := clock.Now()
+		tick = tick.Add(interval)
+		if tick.Before(now) {
+			// dropped the missed ticks
+			tick = tick.Add(now.Sub(tick) / interval * interval)
+		}
+		r := &state[Item[T]]{}
+		res := &Future[Item[T]]{state: r}
+		CtxDelay(ctx, tick.Sub(now), f).state.subscribe(func(val T, err error) {
+			if err != nil {
+				// set before completing the Future, so that it is visible to the next call of Next
+				terminal = res
+			}
+			r.set(Item[T]{Val: val}, err)
+		})
+		return res
+	})
+}
+
+// schedule calls f with nil after d measured by c, or with ctx.Err() if ctx is done before, exactly once.
+//
+// If ctx can be cancelled, a goroutine waits for ctx until the timer fires, so that the timer is stopped
+// as soon as ctx is done.
+func schedule(ctx context.Context, c Clock, d time.Duration, f func(err error)) {
+	if err := ctx.Err(); err != nil {
+		f(err)
+		return
+	}
+	var once uint32
+	fire := func(err error) {
+		if atomic.CompareAndSwapUint32(&once, 0, 1) {
+			f(err)
+		}
+	}
+	done := ctx.Done()
+	if done == nil {
+		c.AfterFunc(d, func() {
+			fire(nil)
+		})
+		return
+	}
+	fired := make(chan struct{})
+	timer := c.AfterFunc(d, func() {
+		close(fired)
+		fire(nil)
+	})
+	go func() {
+		select {
+		case <-done:
+			timer.Stop()
+			fire(ctx.Err())
+		case <-fired:
+		}
+	}()
+}
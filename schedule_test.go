@@ -0,0 +1,91 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfter(t *testing.T) {
+	start := time.Now()
+	now, err := After(10 * time.Millisecond).Get()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, now.Sub(start), 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := CtxAfter(ctx, time.Hour)
+	cancel()
+	_, err = f.Get()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestDelay(t *testing.T) {
+	start := time.Now()
+	val, err := Delay(10*time.Millisecond, func() (int, error) {
+		return 1, nil
+	}).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, val)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	val, err = ScheduleAt(time.Now().Add(-time.Second), func() (int, error) {
+		return 2, nil
+	}).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, val)
+
+	var called int32
+	ctx, cancel := context.WithCancel(context.Background())
+	f := CtxScheduleAt(ctx, time.Now().Add(time.Hour), func(ctx context.Context) (int, error) {
+		atomic.StoreInt32(&called, 1)
+		return 3, nil
+	})
+	cancel()
+	_, err = f.Get()
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&called))
+
+	_, err = CtxDelay(ctx, 0, func(ctx context.Context) (int, error) {
+		return 4, nil
+	}).Get()
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestEvery(t *testing.T) {
+	var calls int32
+	start := time.Now()
+	vals, err := Collect(Take(Every(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int32, error) {
+		return atomic.AddInt32(&calls, 1), nil
+	}), 3)).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3}, vals)
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	errFoo := errors.New("foo")
+	s := Every(context.Background(), time.Millisecond, func(ctx context.Context) (int, error) {
+		return 0, errFoo
+	})
+	_, err = Collect(s).Get()
+	assert.Equal(t, errFoo, err)
+	_, err = s.Next().Get()
+	assert.Equal(t, errFoo, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s = Every(ctx, time.Hour, func(ctx context.Context) (int, error) {
+		return 0, nil
+	})
+	f := s.Next()
+	cancel()
+	_, err = f.Get()
+	assert.Equal(t, context.Canceled, err)
+
+	assert.Panics(t, func() {
+		Every(ctx, 0, func(ctx context.Context) (int, error) {
+			return 0, nil
+		})
+	})
+}
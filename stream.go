@@ -0,0 +1,320 @@
+package future
+
+import (
+	"sync"
+)
+
+// Item is an element of a Stream. Done is true if the stream has ended, in which case Val is the zero value.
+type Item[T any] struct {
+	Val  T
+	Done bool
+}
+
+// Stream is an asynchronous sequence of values, e.g. tokens of an LLM response or pages of a paginated API.
+//
+// Next requests the next element and returns a Future of it. The Future is completed with an Item
+// whose Done is true when the stream has ended, or fails with the error terminating the stream.
+// After the end or an error, Next keeps returning the same terminal result.
+//
+// Streams are pull-based: a producer produces an element only when it is requested by Next,
+// so that a slow consumer slows down the producer (back-pressure), and Buffer allows the producer to run
+// ahead of the consumer by a bounded number of elements. Next must not be called until the Future of
+// the previous Next is done.
+type Stream[T any] interface {
+	Next() *Future[Item[T]]
+}
+
+// StreamFunc is an adapter to allow the use of ordinary functions as Stream.
+type StreamFunc[T any] func() *Future[Item[T]]
+
+// Next calls f().
+func (f StreamFunc[T]) Next() *Future[Item[T]] {
+	return f()
+}
+
+// StreamOf returns a Stream of vals.
+func StreamOf[T any](vals ...T) Stream[T] {
+	i := 0
+	return StreamFunc[T](func() *Future[Item[T]] {
+		if i >= len(vals) {
+			return Done(Item[T]{Done: true})
+		}
+		i++
+		return Done(Item[T]{Val: vals[i-1]})
+	})
+}
+
+// Map returns a Stream of the results of fn applied to each element of s.
+// If fn fails, the returned Stream terminates with the error.
+func Map[T any, R any](s Stream[T], fn func(T) (R, error)) Stream[R] {
+	var terminal *Future[Item[R]]
+	return StreamFunc[R](func() *Future[Item[R]] {
+		if terminal != nil {
+			return terminal
+		}
+		r := &state[Item[R]]{}
+		f := &Future[Item[R]]{state: r}
+		s.Next().state.subscribe(func(it Item[T], err error) {
+			var val R
+			if err == nil && !it.Done {
+				val, err = fn(it.Val)
+			}
+			if err != nil || it.Done {
+				// set before completing f, so that it is visible to the next call of Next
+				terminal = f
+			}
+			r.set(Item[R]{Val: val, Done: it.Done}, err)
+		})
+		return f
+	})
+}
+
+// Filter returns a Stream of the elements of s satisfying pred.
+func Filter[T any](s Stream[T], pred func(T) bool) Stream[T] {
+	return StreamFunc[T](func() *Future[Item[T]] {
+		r := &state[Item[T]]{}
+		pull(s, func(it Item[T], err error) bool {
+			if err != nil || it.Done || pred(it.Val) {
+				r.set(it, err)
+				return false
+			}
+			return true
+		})
+		return &Future[Item[T]]{state: r}
+	})
+}
+
+// Take returns a Stream of the first n elements of s. The elements after them are never requested from s.
+func Take[T any](s Stream[T], n int) Stream[T] {
+	return StreamFunc[T](func() *Future[Item[T]] {
+		if n <= 0 {
+			return Done(Item[T]{Done: true})
+		}
+		n--
+		return s.Next()
+	})
+}
+
+// Collect returns a Future of all elements of s, which is completed when s ends or fails with the error of s.
+func Collect[T any](s Stream[T]) *Future[[]T] {
+	r := &state[[]T]{}
+	var vals []T
+	pull(s, func(it Item[T], err error) bool {
+		if err != nil {
+			r.set(nil, err)
+			return false
+		}
+		if it.Done {
+			r.set(vals, nil)
+			return false
+		}
+		vals = append(vals, it.Val)
+		return true
+	})
+	return &Future[[]T]{state: r}
+}
+
+// pull requests elements from s and calls cb with each result until cb returns false.
+// Elements already done are handled in a loop, so that the stack does not grow with synchronous streams.
+func pull[T any](s Stream[T], cb func(Item[T], error) bool) {
+	for {
+		f := s.Next()
+		if !f.Done() {
+			f.state.subscribe(func(it Item[T], err error) {
+				if cb(it, err) {
+					pull(s, cb)
+				}
+			})
+			return
+		}
+		if !cb(f.Get()) {
+			return
+		}
+	}
+}
+
+// Buffer returns a Stream which requests up to n elements of s ahead of its consumer,
+// so that a producer with high latency can run concurrently with the consumer.
+// A non-positive n means 1.
+func Buffer[T any](s Stream[T], n int) Stream[T] {
+	if n <= 0 {
+		n = 1
+	}
+	b := &buffer[T]{src: s, size: n}
+	return StreamFunc[T](b.next)
+}
+
+type buffer[T any] struct {
+	src  Stream[T]
+	size int
+
+	mu       sync.Mutex
+	queue    []Item[T]
+	terminal *Future[Item[T]] // set when src has ended or failed
+	pulling  bool
+	waiter   *state[Item[T]]
+}
+
+func (b *buffer[T]) next() *Future[Item[T]] {
+	b.mu.Lock()
+	if len(b.queue) > 0 {
+		it := b.queue[0]
+		b.queue = b.queue[1:]
+		start := b.reserve()
+		b.mu.Unlock()
+		if start {
+			b.pull()
+		}
+		return Done(it)
+	}
+	if b.terminal != nil {
+		b.mu.Unlock()
+		return b.terminal
+	}
+	w := &state[Item[T]]{}
+	b.waiter = w
+	start := b.reserve()
+	b.mu.Unlock()
+	if start {
+		b.pull()
+	}
+	return &Future[Item[T]]{state: w}
+}
+
+// reserve reports whether the next element of src should be requested, i.e. the buffer is not full
+// and no request is in flight, must be called with b.mu held.
+func (b *buffer[T]) reserve() bool {
+	if b.pulling || b.terminal != nil || len(b.queue) >= b.size {
+		return false
+	}
+	b.pulling = true
+	return true
+}
+
+// pull requests the next element of src after reserved, the element is handed over to the waiting
+// consumer if any, otherwise it is buffered.
+func (b *buffer[T]) pull() {
+	f := b.src.Next()
+	f.state.subscribe(func(it Item[T], err error) {
+		b.mu.Lock()
+		b.pulling = false
+		if err != nil || it.Done {
+			b.terminal = f
+		} else if b.waiter == nil {
+			b.queue = append(b.queue, it)
+		}
+		w := b.waiter
+		b.waiter = nil
+		start := b.reserve()
+		b.mu.Unlock()
+		if w != nil {
+			w.set(it, err)
+		}
+		if start {
+			b.pull()
+		}
+	})
+}
+
+// Merge returns a Stream of the elements of all ss in the order they arrive. It requests at most one element
+// ahead from each of ss, ends when all of ss have ended, and terminates with the first error of any of ss.
+func Merge[T any](ss ...Stream[T]) Stream[T] {
+	m := &merger[T]{srcs: ss, live: len(ss)}
+	if len(ss) == 0 {
+		m.terminal = Done(Item[T]{Done: true})
+	}
+	return StreamFunc[T](m.next)
+}
+
+type merger[T any] struct {
+	srcs []Stream[T]
+
+	mu       sync.Mutex
+	started  bool
+	failed   bool
+	ready    []mergeItem[T]
+	live     int // number of sources not ended
+	terminal *Future[Item[T]]
+	waiter   *state[Item[T]]
+}
+
+type mergeItem[T any] struct {
+	src int
+	val T
+}
+
+func (m *merger[T]) next() *Future[Item[T]] {
+	m.mu.Lock()
+	if !m.started && m.terminal == nil {
+		m.started = true
+		w := &state[Item[T]]{}
+		m.waiter = w
+		m.mu.Unlock()
+		for i := range m.srcs {
+			m.pull(i)
+		}
+		return &Future[Item[T]]{state: w}
+	}
+	if len(m.ready) > 0 {
+		it := m.ready[0]
+		m.ready = m.ready[1:]
+		m.mu.Unlock()
+		m.pull(it.src)
+		return Done(Item[T]{Val: it.val})
+	}
+	if m.terminal != nil {
+		m.mu.Unlock()
+		return m.terminal
+	}
+	w := &state[Item[T]]{}
+	m.waiter = w
+	m.mu.Unlock()
+	return &Future[Item[T]]{state: w}
+}
+
+// pull requests the next element of the i-th source.
+func (m *merger[T]) pull(i int) {
+	m.srcs[i].Next().state.subscribe(func(it Item[T], err error) {
+		m.mu.Lock()
+		if m.failed {
+			// terminated by an error of another source
+			m.mu.Unlock()
+			return
+		}
+		var result *Future[Item[T]]
+		repull := false
+		switch {
+		case err != nil:
+			m.failed = true
+			m.terminal = Done2(Item[T]{}, err)
+			m.ready = nil
+			result = m.terminal
+		case it.Done:
+			m.live--
+			if m.live == 0 {
+				m.terminal = Done(Item[T]{Done: true})
+				if len(m.ready) == 0 {
+					result = m.terminal
+				}
+			}
+		case m.waiter != nil:
+			result = Done(it)
+			repull = true
+		default:
+			m.ready = append(m.ready, mergeItem[T]{src: i, val: it.Val})
+		}
+		w := m.waiter
+		if result == nil {
+			w = nil
+		} else {
+			m.waiter = nil
+		}
+		m.mu.Unlock()
+		if w != nil {
+			w.set(result.Get())
+		}
+		if repull {
+			m.pull(i)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package future
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counting returns a Stream of 0..n-1 produced asynchronously, and records the number of requested elements.
+func counting(n int, requested *int32) Stream[int] {
+	i := 0
+	return StreamFunc[int](func() *Future[Item[int]] {
+		if i >= n {
+			return Done(Item[int]{Done: true})
+		}
+		atomic.AddInt32(requested, 1)
+		val := i
+		i++
+		return Async(func() (Item[int], error) {
+			return Item[int]{Val: val}, nil
+		})
+	})
+}
+
+func TestStreamOf(t *testing.T) {
+	s := StreamOf(1, 2)
+	it, err := s.Next().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, Item[int]{Val: 1}, it)
+	_, _ = s.Next().Get()
+	it, _ = s.Next().Get()
+	assert.True(t, it.Done)
+	it, _ = s.Next().Get()
+	assert.True(t, it.Done)
+
+	vals := make([]int, 100000)
+	got, err := Collect(StreamOf(vals...)).Get()
+	assert.NoError(t, err)
+	assert.Len(t, got, len(vals))
+}
+
+func TestStreamOperators(t *testing.T) {
+	var requested int32
+	s := Map(Filter(counting(100, &requested), func(v int) bool {
+		return v%2 == 0
+	}), func(v int) (int, error) {
+		return v * 10, nil
+	})
+	vals, err := Collect(Take(s, 3)).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 20, 40}, vals)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requested), "only requested elements are produced")
+}
+
+func TestStreamError(t *testing.T) {
+	errFoo := errors.New("foo")
+	var calls int32
+	s := Map(StreamOf(1, 2, 3), func(v int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if v == 2 {
+			return 0, errFoo
+		}
+		return v, nil
+	})
+	_, err := Collect(s).Get()
+	assert.Equal(t, errFoo, err)
+	_, err = s.Next().Get()
+	assert.Equal(t, errFoo, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestStreamBuffer(t *testing.T) {
+	var requested int32
+	s := Buffer(counting(10, &requested), 3)
+	it, err := s.Next().Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, it.Val)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&requested) == 4
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requested), "bounded by the buffer size")
+
+	vals, err := Collect(s).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, vals)
+	it, _ = s.Next().Get()
+	assert.True(t, it.Done)
+}
+
+func TestStreamMerge(t *testing.T) {
+	var r1, r2 int32
+	vals, err := Collect(Merge(counting(3, &r1), counting(4, &r2), StreamOf[int]())).Get()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{0, 1, 2, 0, 1, 2, 3}, vals)
+
+	vals, err = Collect(Merge[int]()).Get()
+	assert.NoError(t, err)
+	assert.Empty(t, vals)
+
+	errFoo := errors.New("foo")
+	var failing Stream[int] = StreamFunc[int](func() *Future[Item[int]] {
+		return Done2(Item[int]{}, errFoo)
+	})
+	_, err = Collect(Merge(counting(100, &r1), failing)).Get()
+	assert.Equal(t, errFoo, err)
+}
@@ -0,0 +1,266 @@
+package future
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// CountDownLatch allows goroutines to wait until a set of operations being performed in other goroutines
+// completes, like java.util.concurrent.CountDownLatch. It is not reusable, see CyclicBarrier.
+//
+// Waiting is built on the same lock-free state as Future, so the waiters are parked by the runtime semaphore.
+type CountDownLatch struct {
+	count int64
+	done  signal
+}
+
+// NewCountDownLatch creates a CountDownLatch with the given count. A non-positive count is already done.
+func NewCountDownLatch(count int) *CountDownLatch {
+	l := &CountDownLatch{count: int64(count)}
+	if count <= 0 {
+		l.done.set(struct{}{}, nil)
+	}
+	return l
+}
+
+// CountDown decrements the count, and releases all waiters when the count reaches zero.
+// It is a no-op if the count is already zero.
+func (l *CountDownLatch) CountDown() {
+	for {
+		c := atomic.LoadInt64(&l.count)
+		if c <= 0 {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&l.count, c, c-1) {
+			if c == 1 {
+				l.done.set(struct{}{}, nil)
+			}
+			return
+		}
+	}
+}
+
+// Count returns the current count.
+func (l *CountDownLatch) Count() int {
+	return int(atomic.LoadInt64(&l.count))
+}
+
+// Wait blocks until the count reaches zero.
+func (l *CountDownLatch) Wait() {
+	_, _ = l.done.get()
+}
+
+// WaitCtx is like Wait but returns ctx.Err() if ctx is done before the count reaches zero.
+func (l *CountDownLatch) WaitCtx(ctx context.Context) error {
+	return l.done.waitContext(ctx)
+}
+
+// Future returns a Future which is completed when the count reaches zero.
+func (l *CountDownLatch) Future() *Future[struct{}] {
+	return &Future[struct{}]{state: &l.done.state}
+}
+
+// ErrBrokenBarrier is returned to the waiting parties of a CyclicBarrier if the action of the cycle panicked.
+var ErrBrokenBarrier = errors.New("broken barrier")
+
+// CyclicBarrier allows a fixed number of goroutines (parties) to wait for each other to reach a common point,
+// like java.util.concurrent.CyclicBarrier. It is reusable: once all parties arrived, the barrier is tripped
+// and reset for the next cycle.
+type CyclicBarrier struct {
+	parties int
+	action  func()
+
+	mu  sync.Mutex
+	cur *barrierGeneration
+}
+
+type barrierGeneration struct {
+	waiting []*barrierParty // in arrival order
+	tripped signal
+}
+
+type barrierParty struct {
+	index int // assigned when tripped
+}
+
+// NewCyclicBarrier creates a CyclicBarrier for the given number of parties. If action is not nil,
+// it is called by the last arriving party before the others are released. If action panics, the panic
+// propagates to the last arriving party, and the others fail with ErrBrokenBarrier, while the barrier is
+// still reset for the next cycle. NewCyclicBarrier panics if parties is not positive.
+func NewCyclicBarrier(parties int, action func()) *CyclicBarrier {
+	if parties <= 0 {
+		panic("non-positive parties for CyclicBarrier")
+	}
+	return &CyclicBarrier{parties: parties, action: action, cur: &barrierGeneration{}}
+}
+
+// Parties returns the number of parties required to trip the barrier.
+func (b *CyclicBarrier) Parties() int {
+	return b.parties
+}
+
+// Waiting returns the number of parties currently waiting at the barrier.
+func (b *CyclicBarrier) Waiting() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.cur.waiting)
+}
+
+// Wait blocks until all parties have called Wait in the current cycle, and returns the arrival index
+// of the caller, where 0 is the first one to arrive and parties-1 is the last, or -1 if the barrier is broken.
+func (b *CyclicBarrier) Wait() int {
+	index, _ := b.WaitCtx(context.Background())
+	return index
+}
+
+// WaitCtx is like Wait but returns ctx.Err() if ctx is done before the barrier is tripped,
+// in which case the caller leaves the current cycle, and the barrier still waits for parties arrivals.
+// The arrival indexes are assigned among the parties present when the barrier is tripped.
+func (b *CyclicBarrier) WaitCtx(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	p := &barrierParty{}
+	b.mu.Lock()
+	g := b.cur
+	g.waiting = append(g.waiting, p)
+	if len(g.waiting) < b.parties {
+		b.mu.Unlock()
+		if err := g.tripped.waitContext(ctx); err != nil {
+			b.mu.Lock()
+			if b.cur == g {
+				for i, q := range g.waiting {
+					if q == p {
+						g.waiting = append(g.waiting[:i], g.waiting[i+1:]...)
+						break
+					}
+				}
+				b.mu.Unlock()
+				return -1, err
+			}
+			// tripped concurrently, the arrival counted
+			b.mu.Unlock()
+		}
+		if _, err := g.tripped.get(); err != nil {
+			return -1, err
+		}
+		return p.index, nil
+	}
+	for i, q := range g.waiting {
+		q.index = i
+	}
+	b.cur = &barrierGeneration{}
+	b.mu.Unlock()
+
+	tripped := false
+	defer func() {
+		// the action panicked, release the others with an error instead of blocking them forever
+		if !tripped {
+			g.tripped.set(struct{}{}, ErrBrokenBarrier)
+		}
+	}()
+	if b.action != nil {
+		b.action()
+	}
+	tripped = true
+	g.tripped.set(struct{}{}, nil)
+	return p.index, nil
+}
+
+// Event is a broadcast event, like a manual-reset event: Set wakes up all waiters, and
+// later waiters return immediately until Reset is called.
+//
+// The zero value is an unset Event ready to use.
+type Event struct {
+	s unsafe.Pointer // *signal of the current cycle
+}
+
+func (e *Event) current() *signal {
+	for {
+		s := atomic.LoadPointer(&e.s)
+		if s != nil {
+			return (*signal)(s)
+		}
+		atomic.CompareAndSwapPointer(&e.s, nil, unsafe.Pointer(&signal{}))
+	}
+}
+
+// Set sets the event and wakes up all waiters. It is a no-op if the event is already set.
+func (e *Event) Set() {
+	e.current().set(struct{}{}, nil)
+}
+
+// Reset unsets the event, so that later waiters wait for the next Set. It is a no-op if the event is not set.
+func (e *Event) Reset() {
+	s := e.current()
+	if isDone(atomic.LoadUint64(&s.state.state)) {
+		atomic.CompareAndSwapPointer(&e.s, unsafe.Pointer(s), unsafe.Pointer(&signal{}))
+	}
+}
+
+// IsSet returns true if the event is set.
+func (e *Event) IsSet() bool {
+	return isDone(atomic.LoadUint64(&e.current().state.state))
+}
+
+// Wait blocks until the event is set.
+func (e *Event) Wait() {
+	_, _ = e.current().get()
+}
+
+// WaitCtx is like Wait but returns ctx.Err() if ctx is done before the event is set.
+func (e *Event) WaitCtx(ctx context.Context) error {
+	return e.current().waitContext(ctx)
+}
+
+// Future returns a Future which is completed when the event is set, which is not affected by later Reset.
+func (e *Event) Future() *Future[struct{}] {
+	return &Future[struct{}]{state: &e.current().state}
+}
+
+// signal is a state with a channel closed when the state is done, which is created on the first waitContext
+// and shared by all later ones, so that waiting with contexts which are done first (e.g. timeouts in a loop)
+// never accumulates callbacks on the state.
+type signal struct {
+	state[struct{}]
+	ch unsafe.Pointer // *chan struct{}
+}
+
+// done returns the channel closed when the state is done.
+func (s *signal) done() <-chan struct{} {
+	if p := atomic.LoadPointer(&s.ch); p != nil {
+		return *(*chan struct{})(p)
+	}
+	ch := make(chan struct{})
+	if !atomic.CompareAndSwapPointer(&s.ch, nil, unsafe.Pointer(&ch)) {
+		return *(*chan struct{})(atomic.LoadPointer(&s.ch))
+	}
+	s.subscribe(func(struct{}, error) {
+		close(ch)
+	})
+	return ch
+}
+
+// waitContext waits for the state to be done, or returns ctx.Err() if ctx is done before.
+func (s *signal) waitContext(ctx context.Context) error {
+	if isDone(atomic.LoadUint64(&s.state.state)) {
+		return nil
+	}
+	if ctx.Done() == nil {
+		_, _ = s.get()
+		return nil
+	}
+	select {
+	case <-s.done():
+		return nil
+	case <-ctx.Done():
+		// prefer the state if both are ready
+		if isDone(atomic.LoadUint64(&s.state.state)) {
+			return nil
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,202 @@
+package future
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountDownLatch(t *testing.T) {
+	l := NewCountDownLatch(3)
+	assert.Equal(t, 3, l.Count())
+	f := l.Future()
+
+	for i := 0; i < 3; i++ {
+		go l.CountDown()
+	}
+	l.Wait()
+	assert.True(t, f.Done())
+	assert.Equal(t, 0, l.Count())
+	l.CountDown()
+	assert.Equal(t, 0, l.Count())
+	assert.NoError(t, l.WaitCtx(context.Background()))
+
+	l = NewCountDownLatch(0)
+	assert.True(t, l.Future().Done())
+
+	l = NewCountDownLatch(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, l.WaitCtx(ctx))
+}
+
+func TestCyclicBarrier(t *testing.T) {
+	var actions int32
+	b := NewCyclicBarrier(3, func() {
+		atomic.AddInt32(&actions, 1)
+	})
+	assert.Equal(t, 3, b.Parties())
+
+	for cycle := 0; cycle < 3; cycle++ {
+		var mu sync.Mutex
+		var indexes []int
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				index := b.Wait()
+				assert.Equal(t, int32(cycle+1), atomic.LoadInt32(&actions), "action runs before release")
+				mu.Lock()
+				indexes = append(indexes, index)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		sort.Ints(indexes)
+		assert.Equal(t, []int{0, 1, 2}, indexes)
+		assert.Equal(t, 0, b.Waiting())
+	}
+
+	assert.Panics(t, func() {
+		NewCyclicBarrier(0, nil)
+	})
+}
+
+func TestCyclicBarrierCtx(t *testing.T) {
+	b := NewCyclicBarrier(2, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		_, err := b.WaitCtx(ctx)
+		errCh <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return b.Waiting() == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-errCh)
+	assert.Equal(t, 0, b.Waiting(), "left the cycle")
+
+	_, err := b.WaitCtx(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	done := make(chan int)
+	go func() {
+		done <- b.Wait()
+	}()
+	index, err := b.WaitCtx(context.Background())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{0, 1}, []int{index, <-done})
+}
+
+func TestEvent(t *testing.T) {
+	var e Event
+	assert.False(t, e.IsSet())
+	f := e.Future()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.Wait()
+		}()
+	}
+	e.Set()
+	wg.Wait()
+	assert.True(t, e.IsSet())
+	assert.True(t, f.Done())
+	e.Set()
+	assert.NoError(t, e.WaitCtx(context.Background()))
+
+	e.Reset()
+	assert.False(t, e.IsSet())
+	assert.True(t, f.Done(), "not affected by Reset")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, e.WaitCtx(ctx))
+
+	go e.Set()
+	assert.NoError(t, e.WaitCtx(context.Background()))
+}
+
+func TestWaitCtxNoCallbackLeak(t *testing.T) {
+	var e Event
+	l := NewCountDownLatch(1)
+	for i := 0; i < 100; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+		assert.Error(t, e.WaitCtx(ctx))
+		assert.Error(t, l.WaitCtx(ctx))
+		cancel()
+	}
+	count := func(s *signal) int {
+		n := 0
+		for cb := (*callback[struct{}])(s.stack); cb != nil; cb = cb.next {
+			n++
+		}
+		return n
+	}
+	assert.Equal(t, 1, count(e.current()))
+	assert.Equal(t, 1, count(&l.done))
+}
+
+func TestCyclicBarrierIndexAfterLeave(t *testing.T) {
+	b := NewCyclicBarrier(3, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		_, err := b.WaitCtx(ctx)
+		errCh <- err
+	}()
+	assert.Eventually(t, func() bool { return b.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	indexes := make(chan int, 3)
+	go func() {
+		indexes <- b.Wait()
+	}()
+	assert.Eventually(t, func() bool { return b.Waiting() == 2 }, time.Second, time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-errCh)
+
+	go func() {
+		indexes <- b.Wait()
+	}()
+	assert.Eventually(t, func() bool { return b.Waiting() == 2 }, time.Second, time.Millisecond)
+	indexes <- b.Wait()
+	assert.ElementsMatch(t, []int{0, 1, 2}, []int{<-indexes, <-indexes, <-indexes})
+}
+
+func TestCyclicBarrierActionPanic(t *testing.T) {
+	var panics int32
+	b := NewCyclicBarrier(2, func() {
+		if atomic.AddInt32(&panics, 1) == 1 {
+			panic("foo")
+		}
+	})
+	errCh := make(chan error)
+	go func() {
+		_, err := b.WaitCtx(context.Background())
+		errCh <- err
+	}()
+	assert.Eventually(t, func() bool { return b.Waiting() == 1 }, time.Second, time.Millisecond)
+	assert.PanicsWithValue(t, "foo", func() {
+		b.Wait()
+	})
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, ErrBrokenBarrier)
+	case <-time.After(time.Second):
+		t.Fatal("waiter is not released")
+	}
+
+	// reset for the next cycle
+	go b.Wait()
+	assert.Eventually(t, func() bool { return b.Waiting() == 1 }, time.Second, time.Millisecond)
+	assert.NotEqual(t, -1, b.Wait())
+}
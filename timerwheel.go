@@ -0,0 +1,229 @@
+package future
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// wheelShards is the number of pending stacks, which reduces the contention of creating timers concurrently.
+const wheelShards = 32
+
+const (
+	timerPending uint32 = iota
+	timerFired
+	timerStopped
+)
+
+// TimerWheel is a Clock backed by a hashed timer wheel, which is an optional backend of Timeout and Until.
+//
+// Unlike time.AfterFunc, a timer on the wheel is not a runtime timer. It is pushed to sharded lock-free
+// pending stacks, and a single goroutine moves pending timers into the slots of the wheel and fires the due ones
+// on each tick. As a result, timers fire with the resolution of tick, i.e. between their deadline and one tick later,
+// and callbacks are called in the goroutine of the wheel. Measure with BenchmarkTimeout before choosing it
+// for performance, it is not cheaper than time.AfterFunc per timer.
+//
+// Select it globally or per call:
+//
+//	wheel := future.NewTimerWheel(10*time.Millisecond, 512)
+//	defer wheel.Stop()
+//
+//	future.SetClock(wheel)
+//	f := future.TimeoutWithClock(wheel, f, time.Second)
+type TimerWheel struct {
+	tick  time.Duration
+	start time.Time
+	slots []*wheelTimer
+
+	pending [wheelShards]wheelShard
+	cursor  int64 // last processed tick, only accessed by the wheel goroutine
+	stop    chan struct{}
+	stopped uint32
+}
+
+type wheelShard struct {
+	head unsafe.Pointer // *wheelTimer
+	_    [56]byte       // avoid false sharing between shards
+}
+
+type wheelTimer struct {
+	f     func()
+	tick  int64
+	state uint32
+	next  *wheelTimer
+}
+
+// NewTimerWheel creates and starts a TimerWheel with the given tick resolution and number of slots.
+// Timers longer than tick * slots are kept in the wheel for multiple rounds.
+//
+// Passing non-positive tick or slots will panic.
+func NewTimerWheel(tick time.Duration, slots int) *TimerWheel {
+	if tick <= 0 {
+		panic("tick must be positive")
+	}
+	if slots <= 0 {
+		panic("slots must be positive")
+	}
+	w := &TimerWheel{
+		tick:  tick,
+		start: time.Now(),
+		slots: make([]*wheelTimer, slots),
+		stop:  make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Now returns the current time.
+func (w *TimerWheel) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc schedules f to be called in the goroutine of the wheel after d, with the resolution of tick.
+//
+// After the wheel is stopped, AfterFunc falls back to time.AfterFunc, so that timers created after Stop
+// (e.g. by Timeout during shutdown) still fire.
+func (w *TimerWheel) AfterFunc(d time.Duration, f func()) Timer {
+	if atomic.LoadUint32(&w.stopped) == 1 {
+		return time.AfterFunc(d, f)
+	}
+	elapsed := time.Since(w.start)
+	t := &wheelTimer{f: f, tick: math.MaxInt64}
+	if d < math.MaxInt64-elapsed-w.tick {
+		// round up, so that the timer never fires before its deadline
+		t.tick = int64((elapsed + d + w.tick - 1) / w.tick)
+	}
+	// the address of the timer is a cheap source of randomness to pick a shard
+	shard := &w.pending[(uintptr(unsafe.Pointer(t))>>6)%wheelShards]
+	for {
+		head := atomic.LoadPointer(&shard.head)
+		t.next = (*wheelTimer)(head)
+		if atomic.CompareAndSwapPointer(&shard.head, head, unsafe.Pointer(t)) {
+			break
+		}
+	}
+	// stopped concurrently, the timer may never be processed by the wheel
+	if atomic.LoadUint32(&w.stopped) == 1 && t.Stop() {
+		return time.AfterFunc(d-time.Since(w.start)+elapsed, f)
+	}
+	return t
+}
+
+// Stop stops the goroutine of the wheel. Timers created before Stop and not fired yet are moved to time.AfterFunc
+// for their remaining time, and timers created after Stop fall back to time.AfterFunc, so that no timer is lost.
+func (w *TimerWheel) Stop() {
+	if atomic.CompareAndSwapUint32(&w.stopped, 0, 1) {
+		close(w.stop)
+	}
+}
+
+func (w *TimerWheel) run() {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			w.drain()
+			return
+		case now := <-ticker.C:
+			w.advance(int64(now.Sub(w.start) / w.tick))
+		}
+	}
+}
+
+// advance moves the cursor to target and fires all timers due until target.
+func (w *TimerWheel) advance(target int64) {
+	if target <= w.cursor {
+		return
+	}
+
+	for i := range w.pending {
+		pending := (*wheelTimer)(atomic.SwapPointer(&w.pending[i].head, nil))
+		for pending != nil {
+			t := pending
+			pending = t.next
+			t.next = nil
+			if atomic.LoadUint32(&t.state) != timerPending {
+				continue
+			}
+			if t.tick <= target {
+				t.fire()
+				continue
+			}
+			slot := t.tick % int64(len(w.slots))
+			t.next = w.slots[slot]
+			w.slots[slot] = t
+		}
+	}
+
+	from := w.cursor + 1
+	if target-from >= int64(len(w.slots)) {
+		// fallen behind more than a round, each slot only needs to be processed once
+		from = target - int64(len(w.slots)) + 1
+	}
+	for c := from; c <= target; c++ {
+		w.expire(c%int64(len(w.slots)), target)
+	}
+	w.cursor = target
+}
+
+// expire fires the timers of slot due until target, and keeps the others for later rounds.
+func (w *TimerWheel) expire(slot int64, target int64) {
+	var keep *wheelTimer
+	t := w.slots[slot]
+	for t != nil {
+		next := t.next
+		t.next = nil
+		if atomic.LoadUint32(&t.state) == timerPending {
+			if t.tick <= target {
+				t.fire()
+			} else {
+				t.next = keep
+				keep = t
+			}
+		}
+		t = next
+	}
+	w.slots[slot] = keep
+}
+
+// drain moves all pending timers of the stopped wheel to time.AfterFunc for their remaining time.
+func (w *TimerWheel) drain() {
+	move := func(t *wheelTimer) {
+		for t != nil {
+			next := t.next
+			t.next = nil
+			if atomic.LoadUint32(&t.state) == timerPending {
+				time.AfterFunc(w.remaining(t), t.fire)
+			}
+			t = next
+		}
+	}
+	for i := range w.pending {
+		move((*wheelTimer)(atomic.SwapPointer(&w.pending[i].head, nil)))
+	}
+	for i := range w.slots {
+		move(w.slots[i])
+		w.slots[i] = nil
+	}
+}
+
+// remaining returns the time until the tick of t.
+func (w *TimerWheel) remaining(t *wheelTimer) time.Duration {
+	if t.tick >= math.MaxInt64/int64(w.tick) {
+		return math.MaxInt64
+	}
+	return time.Duration(t.tick)*w.tick - time.Since(w.start)
+}
+
+func (t *wheelTimer) fire() {
+	if atomic.CompareAndSwapUint32(&t.state, timerPending, timerFired) {
+		t.f()
+	}
+}
+
+// Stop prevents the timer from firing, stopped timers are dropped lazily by the wheel.
+func (t *wheelTimer) Stop() bool {
+	return atomic.CompareAndSwapUint32(&t.state, timerPending, timerStopped)
+}
@@ -0,0 +1,159 @@
+package future
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerWheel(t *testing.T) {
+	w := NewTimerWheel(time.Millisecond, 8)
+	defer w.Stop()
+
+	start := time.Now()
+	var fired [3]int64
+	wg := sync.WaitGroup{}
+	for i, d := range []time.Duration{0, 5 * time.Millisecond, 20 * time.Millisecond} {
+		i, d := i, d
+		wg.Add(1)
+		w.AfterFunc(d, func() {
+			defer wg.Done()
+			atomic.StoreInt64(&fired[i], int64(time.Since(start)))
+			assert.GreaterOrEqual(t, time.Since(start), d)
+		})
+	}
+	stopped := w.AfterFunc(10*time.Millisecond, func() {
+		assert.Fail(t, "stopped timer fired")
+	})
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	wg.Wait()
+
+	// the timer longer than a round (8ms) fires after the shorter ones
+	assert.Less(t, atomic.LoadInt64(&fired[0]), atomic.LoadInt64(&fired[1]))
+	assert.Less(t, atomic.LoadInt64(&fired[1]), atomic.LoadInt64(&fired[2]))
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestTimerWheelAdvance(t *testing.T) {
+	w := &TimerWheel{tick: time.Millisecond, start: time.Now(), slots: make([]*wheelTimer, 4)}
+
+	var order []int
+	for _, i := range []int{3, 1, 10, 6} {
+		i := i
+		w.AfterFunc(time.Duration(i)*time.Millisecond, func() {
+			order = append(order, i)
+		})
+	}
+	w.advance(2)
+	assert.Equal(t, []int{1}, order)
+	w.advance(2)
+	assert.Equal(t, []int{1}, order)
+	w.advance(7)
+	assert.Equal(t, []int{1, 3, 6}, order)
+	// fallen behind more than a round
+	w.advance(100)
+	assert.Equal(t, []int{1, 3, 6, 10}, order)
+}
+
+func TestTimerWheelTimeout(t *testing.T) {
+	w := NewTimerWheel(time.Millisecond, 64)
+	defer w.Stop()
+
+	{
+		f := TimeoutWithClock(w, NewPromise[int]().Future(), 5*time.Millisecond)
+		_, err := f.Get()
+		assert.ErrorIs(t, err, ErrTimeout)
+	}
+	{
+		f := UntilWithClock(w, Async(func() (int, error) {
+			return 1, nil
+		}), time.Now().Add(time.Second))
+		val, err := f.Get()
+		assert.Equal(t, 1, val)
+		assert.NoError(t, err)
+	}
+}
+
+func TestTimerWheelInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewTimerWheel(0, 1)
+	})
+	assert.Panics(t, func() {
+		NewTimerWheel(time.Millisecond, 0)
+	})
+}
+
+func BenchmarkTimeout(b *testing.B) {
+	b.Run("Runtime", func(b *testing.B) {
+		benchmarkTimeout(b, SystemClock())
+	})
+	b.Run("TimerWheel", func(b *testing.B) {
+		w := NewTimerWheel(10*time.Millisecond, 512)
+		defer w.Stop()
+		benchmarkTimeout(b, w)
+	})
+}
+
+// benchmarkTimeout creates timeouts which are usually not triggered, that is the common case of RPC.
+func benchmarkTimeout(b *testing.B, c Clock) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p := NewPromise[int]()
+			f := TimeoutWithClock(c, p.Future(), time.Second)
+			p.Set(1, nil)
+			_, _ = f.Get()
+		}
+	})
+}
+
+func TestTimerWheelLongTimer(t *testing.T) {
+	w := NewTimerWheel(time.Millisecond, 64)
+	defer w.Stop()
+
+	var fired int32
+	timer := w.AfterFunc(math.MaxInt64, func() {
+		atomic.StoreInt32(&fired, 1)
+	})
+	f := TimeoutWithClock(w, NewPromise[int]().Future(), math.MaxInt64)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&fired))
+	assert.False(t, f.Done())
+	assert.True(t, timer.Stop())
+}
+
+func TestTimerWheelAfterStop(t *testing.T) {
+	w := NewTimerWheel(time.Millisecond, 64)
+	w.Stop()
+
+	f := TimeoutWithClock(w, NewPromise[int]().Future(), 5*time.Millisecond)
+	_, err := f.Get()
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestTimerWheelStopPending(t *testing.T) {
+	w := NewTimerWheel(time.Millisecond, 64)
+
+	f := TimeoutWithClock(w, NewPromise[int]().Future(), 20*time.Millisecond)
+	var fired int32
+	timer := w.AfterFunc(20*time.Millisecond, func() {
+		atomic.StoreInt32(&fired, 1)
+	})
+	time.Sleep(5 * time.Millisecond) // moved into the slots by the wheel
+	stopped := w.AfterFunc(20*time.Millisecond, func() {
+		atomic.StoreInt32(&fired, 2)
+	})
+	assert.True(t, stopped.Stop())
+	w.Stop()
+
+	_, err := f.Get()
+	assert.ErrorIs(t, err, ErrTimeout)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fired))
+	assert.False(t, timer.Stop())
+}